@@ -35,15 +35,38 @@ type StatisticGroup string
 const (
 	ServerStats StatisticGroup = "server"
 	ViewStats   StatisticGroup = "view"
+	ZoneStats   StatisticGroup = "zones"
 	TaskStats   StatisticGroup = "tasks"
+	// TransferStats are the incoming zone transfers, available since BIND
+	// 9.20.
+	TransferStats StatisticGroup = "transfers"
+	// ListenerStats are the sockets of the socket manager, which was replaced
+	// in BIND 9.18.
+	ListenerStats StatisticGroup = "listeners"
 )
 
 // Statistics is a generic representation of BIND statistics.
 type Statistics struct {
-	Server      Server
-	Views       []View
-	ZoneViews   []ZoneView
-	TaskManager TaskManager
+	// Version is the BIND version and SchemaVersion the version of the
+	// statistics document format, e.g. "9.18.12" and "1.7", if reported.
+	Version       string
+	SchemaVersion string
+	// Unsupported lists the requested groups the server doesn't provide.
+	Unsupported []StatisticGroup
+
+	Server    Server
+	Views     []View
+	ZoneViews []ZoneView
+	// TaskManager is nil if the server doesn't report a task manager, which
+	// was removed in BIND 9.20.
+	TaskManager *TaskManager
+	// LoopManager is set instead of TaskManager by servers running the event
+	// loops that replaced it.
+	LoopManager *LoopManager
+	// TransferViews are the incoming zone transfers by view.
+	TransferViews []TransferView
+	// Sockets are the sockets of the socket manager.
+	Sockets []Socket
 }
 
 // Server represents BIND server statistics.
@@ -55,6 +78,7 @@ type Server struct {
 	NameServerStats  []Counter
 	ZoneStatistics   []Counter
 	ServerRcodes     []Counter
+	SocketStatistics []Counter
 }
 
 // View represents statistics for a single BIND view.
@@ -71,12 +95,53 @@ type ZoneView struct {
 	ZoneData []ZoneCounter
 }
 
-// TaskManager contains information about all running tasks.
+// TransferView represents the incoming zone transfers of a view.
+type TransferView struct {
+	Name      string
+	Transfers []Transfer
+}
+
+// Transfer represents an incoming zone transfer, either in progress or
+// waiting to be started.
+type Transfer struct {
+	Zone    string
+	Primary string
+	State   string
+	// Type is the transfer type, AXFR or IXFR, once it is known.
+	Type     string
+	Duration time.Duration
+	Messages uint64
+	Records  uint64
+	Bytes    uint64
+}
+
+// Socket represents a socket of the socket manager.
+type Socket struct {
+	ID   string
+	Type string
+	// LocalAddress is the address the socket is bound to, formatted as
+	// address#port.
+	LocalAddress string
+	References   uint64
+	// States are e.g. "bound", "listener" and "connected".
+	States []string
+}
+
+// TaskManager contains information about all running tasks. Tasks isn't
+// populated by the statistics clients, as the list can be very large and isn't
+// exported.
 type TaskManager struct {
 	Tasks       []Task      `xml:"tasks>task"`
 	ThreadModel ThreadModel `xml:"thread-model"`
 }
 
+// LoopManager contains information about the event loops of BIND 9.20 and
+// later.
+type LoopManager struct {
+	// Loops is the number of event loops, each running on its own thread.
+	Loops uint64 `xml:"loops"`
+}
+
 // Counter represents a single counter value.
 type Counter struct {
 	Name    string `xml:"name,attr"`
@@ -87,6 +152,17 @@ type Counter struct {
 type ZoneCounter struct {
 	Name   string
 	Serial string
+	// DNSSECSign and DNSSECRefresh count the signatures generated per key
+	// tag, for new and for expiring signatures respectively.
+	DNSSECSign    []Counter
+	DNSSECRefresh []Counter
+}
+
+// Loaded reports whether the zone is loaded. BIND keeps zones which failed to
+// load, e.g. because of a syntax error, in the statistics with serial -1 in
+// JSON and - in XML.
+func (z ZoneCounter) Loaded() bool {
+	return z.Serial != "-1" && z.Serial != "-"
 }
 
 // Gauge represents a single gauge value.
@@ -0,0 +1,112 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnssec
+
+import (
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+var minExpiry = time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestReadKeys(t *testing.T) {
+	keys, err := ReadKeys("../../fixtures/dnssec/keys", "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Key{
+		{KeyTag: 2371, Algorithm: "ECDSAP256SHA256", Role: KSK, Events: map[string]time.Time{
+			Publish:  date(2026, 1, 1),
+			Activate: date(2026, 1, 2),
+			Retire:   date(2027, 1, 1),
+			Delete:   date(2027, 2, 1),
+		}},
+		// The .state file takes precedence over the .private file.
+		{KeyTag: 34505, Algorithm: "ECDSAP256SHA256", Role: CSK, Events: map[string]time.Time{
+			Publish:  date(2026, 1, 1),
+			Activate: date(2026, 1, 1),
+		}},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("unexpected keys:\n%+v\nwant:\n%+v", keys, want)
+	}
+
+	if keys, err := ReadKeys("../../fixtures/dnssec/keys", "example.org"); err != nil || len(keys) != 0 {
+		t.Errorf("want no keys for another zone, got %+v: %v", keys, err)
+	}
+}
+
+func TestFileExpiry(t *testing.T) {
+	e, err := FileExpiry("../../fixtures/dnssec/example.com.db.signed", "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Equal(minExpiry) {
+		t.Errorf("want expiry %s, got %s", minExpiry, e)
+	}
+
+	if _, err := FileExpiry("../../fixtures/dnssec/example.com.db", "example.com"); err != ErrUnsigned {
+		t.Errorf("want ErrUnsigned for unsigned zone, got %v", err)
+	}
+}
+
+func TestTransferExpiry(t *testing.T) {
+	f, err := os.Open("../../fixtures/dnssec/example.com.db.signed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var rrs []dns.RR
+	zp := dns.NewZoneParser(f, "example.com.", "")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		rrs = append(rrs, rr)
+	}
+	// A zone transfer starts and ends with the SOA record.
+	rrs = append(rrs, rrs[0])
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &dns.Server{Listener: l, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		ch := make(chan *dns.Envelope)
+		tr := new(dns.Transfer)
+		go func() {
+			ch <- &dns.Envelope{RR: rrs[:3]}
+			ch <- &dns.Envelope{RR: rrs[3:]}
+			close(ch)
+		}()
+		tr.Out(w, r, ch)
+		w.Close()
+	})}
+	go s.ActivateAndServe()
+	defer s.Shutdown()
+
+	e, err := TransferExpiry(l.Addr().String(), "example.com", nil, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Equal(minExpiry) {
+		t.Errorf("want expiry %s, got %s", minExpiry, e)
+	}
+}
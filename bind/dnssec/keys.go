@@ -0,0 +1,190 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dnssec inspects the DNSSEC keys and signatures of zones.
+package dnssec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Key roles.
+const (
+	KSK = "ksk"
+	ZSK = "zsk"
+	// CSK is a key used both as KSK and ZSK.
+	CSK = "csk"
+)
+
+// Key events in the order they happen during the lifetime of a key.
+const (
+	Publish  = "publish"
+	Activate = "activate"
+	Retire   = "retire"
+	Delete   = "delete"
+)
+
+// Events lists the key events in lifetime order.
+var Events = []string{Publish, Activate, Retire, Delete}
+
+// Key is the metadata of a DNSSEC key of a zone.
+type Key struct {
+	KeyTag    uint16
+	Algorithm string
+	Role      string
+	// Events are the scheduled times of the key events which are set.
+	Events map[string]time.Time
+}
+
+// Timing metadata names of the .private and .state files, and the events
+// they map to. .state files are written for keys managed by dnssec-policy
+// and take precedence.
+var (
+	privateTimes = map[string]string{
+		"Publish":  Publish,
+		"Activate": Activate,
+		"Inactive": Retire,
+		"Delete":   Delete,
+	}
+	stateTimes = map[string]string{
+		"Published": Publish,
+		"Active":    Activate,
+		"Retired":   Retire,
+		"Removed":   Delete,
+	}
+)
+
+// ReadKeys reads the metadata of the keys of zone from the key files
+// K<zone>.+<algorithm>+<keytag>.key in dir and the accompanying .private and
+// .state files.
+func ReadKeys(dir, zone string) ([]Key, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "K"+strings.ToLower(dns.Fqdn(zone))+"+*+*.key"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, p := range paths {
+		k, err := readKey(strings.TrimSuffix(p, ".key"))
+		if err != nil {
+			return nil, fmt.Errorf("error reading key %s: %s", p, err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// readKey reads the key files with the given path without suffix.
+func readKey(base string) (Key, error) {
+	b, err := os.ReadFile(base + ".key")
+	if err != nil {
+		return Key{}, err
+	}
+	var dnskey *dns.DNSKEY
+	zp := dns.NewZoneParser(strings.NewReader(string(b)), "", base+".key")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		if k, ok := rr.(*dns.DNSKEY); ok {
+			dnskey = k
+			break
+		}
+	}
+	if err := zp.Err(); err != nil {
+		return Key{}, err
+	}
+	if dnskey == nil {
+		return Key{}, errors.New("no DNSKEY record")
+	}
+
+	k := Key{
+		KeyTag:    dnskey.KeyTag(),
+		Algorithm: dns.AlgorithmToString[dnskey.Algorithm],
+		Role:      ZSK,
+		Events:    map[string]time.Time{},
+	}
+	if k.Algorithm == "" {
+		k.Algorithm = strconv.Itoa(int(dnskey.Algorithm))
+	}
+	if dnskey.Flags&dns.SEP != 0 {
+		k.Role = KSK
+	}
+
+	if err := readMetadata(base+".private", func(name, value string) {
+		if e, ok := privateTimes[name]; ok {
+			if t, err := parseTime(value); err == nil {
+				k.Events[e] = t
+			}
+		}
+	}); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Key{}, err
+	}
+
+	var isKSK, isZSK bool
+	err = readMetadata(base+".state", func(name, value string) {
+		switch name {
+		case "KSK":
+			isKSK = value == "yes"
+		case "ZSK":
+			isZSK = value == "yes"
+		}
+		if e, ok := stateTimes[name]; ok {
+			if t, err := parseTime(value); err == nil {
+				k.Events[e] = t
+			}
+		}
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Key{}, err
+	}
+	switch {
+	case isKSK && isZSK:
+		k.Role = CSK
+	case isKSK:
+		k.Role = KSK
+	case isZSK:
+		k.Role = ZSK
+	}
+	return k, nil
+}
+
+// readMetadata calls fn for every "Name: value" line of a key file.
+func readMetadata(path string, fn func(name, value string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if name, value, ok := strings.Cut(s.Text(), ":"); ok {
+			fn(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+	return s.Err()
+}
+
+// parseTime parses a timestamp of the key files, e.g. "20260101000000". A
+// trailing human readable representation is ignored.
+func parseTime(s string) (time.Time, error) {
+	s, _, _ = strings.Cut(s, " ")
+	return time.Parse("20060102150405", s)
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnssec
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/prometheus-community/bind_exporter/bind/namedconf"
+)
+
+// ErrUnsigned is returned for zones without signatures.
+var ErrUnsigned = errors.New("zone has no signatures")
+
+// expiry tracks the earliest signature expiration.
+type expiry struct {
+	min   uint32
+	found bool
+}
+
+func (e *expiry) add(rr dns.RR) {
+	sig, ok := rr.(*dns.RRSIG)
+	if !ok {
+		return
+	}
+	// Expiration times use serial number arithmetic, see RFC 4034.
+	if !e.found || int32(sig.Expiration-e.min) < 0 {
+		e.min = sig.Expiration
+	}
+	e.found = true
+}
+
+func (e *expiry) time() (time.Time, error) {
+	if !e.found {
+		return time.Time{}, ErrUnsigned
+	}
+	// Resolve the 32 bit timestamp to the date closest to now.
+	now := time.Now().Unix()
+	t := now + int64(int32(e.min-uint32(now)))
+	return time.Unix(t, 0), nil
+}
+
+// FileExpiry returns the earliest RRSIG expiration of a zone file in text
+// format.
+func FileExpiry(path, zone string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	var e expiry
+	zp := dns.NewZoneParser(bufio.NewReader(f), dns.Fqdn(zone), path)
+	zp.SetIncludeAllowed(true)
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		e.add(rr)
+	}
+	if err := zp.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return e.time()
+}
+
+// TransferExpiry transfers a zone from addr and returns its earliest RRSIG
+// expiration. The transfer is signed with key unless it is nil.
+func TransferExpiry(addr, zone string, key *namedconf.Key, timeout time.Duration) (time.Time, error) {
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(zone))
+	t := &dns.Transfer{DialTimeout: timeout, ReadTimeout: timeout, WriteTimeout: timeout}
+	if key != nil {
+		name := dns.Fqdn(key.Name)
+		t.TsigSecret = map[string]string{name: key.Secret}
+		m.SetTsig(name, dns.Fqdn(key.Algorithm), 300, time.Now().Unix())
+	}
+	envelopes, err := t.In(m, addr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var e expiry
+	for env := range envelopes {
+		if env.Error != nil {
+			// Drain the channel to let the transfer finish.
+			for range envelopes {
+			}
+			return time.Time{}, env.Error
+		}
+		for _, rr := range env.RR {
+			e.add(rr)
+		}
+	}
+	return e.time()
+}
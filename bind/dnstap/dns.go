@@ -0,0 +1,123 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnstap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var errShortMessage = errors.New("short DNS message")
+
+// Header is the part of a DNS message the exporter is interested in.
+type Header struct {
+	Rcode int
+	// QType is the type of the first question, or zero if the message has
+	// no question.
+	QType uint16
+}
+
+// ParseHeader parses the header and the type of the first question of a DNS
+// message in wire format.
+func ParseHeader(msg []byte) (Header, error) {
+	if len(msg) < 12 {
+		return Header{}, errShortMessage
+	}
+	h := Header{Rcode: int(msg[3] & 0x0f)}
+	if binary.BigEndian.Uint16(msg[4:]) == 0 {
+		return h, nil
+	}
+
+	// Skip the question name. Names in the question section are usually not
+	// compressed, a pointer ends the name nevertheless.
+	off := 12
+	for {
+		if off >= len(msg) {
+			return h, errShortMessage
+		}
+		l := int(msg[off])
+		switch {
+		case l == 0:
+			off++
+		case l&0xc0 == 0xc0:
+			off += 2
+		case l&0xc0 != 0:
+			return h, fmt.Errorf("invalid label type %#x", l&0xc0)
+		default:
+			off += 1 + l
+			continue
+		}
+		break
+	}
+	if off+2 > len(msg) {
+		return h, errShortMessage
+	}
+	h.QType = binary.BigEndian.Uint16(msg[off:])
+	return h, nil
+}
+
+var qtypes = map[uint16]string{
+	1:   "A",
+	2:   "NS",
+	5:   "CNAME",
+	6:   "SOA",
+	12:  "PTR",
+	13:  "HINFO",
+	15:  "MX",
+	16:  "TXT",
+	28:  "AAAA",
+	29:  "LOC",
+	33:  "SRV",
+	35:  "NAPTR",
+	39:  "DNAME",
+	43:  "DS",
+	46:  "RRSIG",
+	47:  "NSEC",
+	48:  "DNSKEY",
+	50:  "NSEC3",
+	51:  "NSEC3PARAM",
+	52:  "TLSA",
+	59:  "CDS",
+	60:  "CDNSKEY",
+	64:  "SVCB",
+	65:  "HTTPS",
+	99:  "SPF",
+	251: "IXFR",
+	252: "AXFR",
+	255: "ANY",
+	257: "CAA",
+}
+
+// QTypeName returns the mnemonic of a query type. Uncommon types are
+// reported as "other" to bound the number of names.
+func QTypeName(t uint16) string {
+	if s, ok := qtypes[t]; ok {
+		return s
+	}
+	return "other"
+}
+
+var rcodes = []string{
+	"NOERROR", "FORMERR", "SERVFAIL", "NXDOMAIN", "NOTIMP", "REFUSED",
+	"YXDOMAIN", "YXRRSET", "NXRRSET", "NOTAUTH", "NOTZONE",
+}
+
+// RcodeName returns the mnemonic of a response code.
+func RcodeName(rcode int) string {
+	if rcode < len(rcodes) {
+		return rcodes[rcode]
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dnstap receives dnstap messages logged by named over a Frame
+// Streams socket.
+//
+// Only the fields of the dnstap schema used by the exporter are decoded, see
+// https://github.com/dnstap/dnstap.pb/blob/master/dnstap.proto.
+package dnstap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/internal/wire"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MessageType is the type of a logged message.
+type MessageType int
+
+// Message types of the dnstap schema.
+const (
+	AuthQuery         MessageType = 1
+	AuthResponse      MessageType = 2
+	ResolverQuery     MessageType = 3
+	ResolverResponse  MessageType = 4
+	ClientQuery       MessageType = 5
+	ClientResponse    MessageType = 6
+	ForwarderQuery    MessageType = 7
+	ForwarderResponse MessageType = 8
+	StubQuery         MessageType = 9
+	StubResponse      MessageType = 10
+	ToolQuery         MessageType = 11
+	ToolResponse      MessageType = 12
+	UpdateQuery       MessageType = 13
+	UpdateResponse    MessageType = 14
+)
+
+var messageTypes = map[MessageType]string{
+	AuthQuery:         "AUTH_QUERY",
+	AuthResponse:      "AUTH_RESPONSE",
+	ResolverQuery:     "RESOLVER_QUERY",
+	ResolverResponse:  "RESOLVER_RESPONSE",
+	ClientQuery:       "CLIENT_QUERY",
+	ClientResponse:    "CLIENT_RESPONSE",
+	ForwarderQuery:    "FORWARDER_QUERY",
+	ForwarderResponse: "FORWARDER_RESPONSE",
+	StubQuery:         "STUB_QUERY",
+	StubResponse:      "STUB_RESPONSE",
+	ToolQuery:         "TOOL_QUERY",
+	ToolResponse:      "TOOL_RESPONSE",
+	UpdateQuery:       "UPDATE_QUERY",
+	UpdateResponse:    "UPDATE_RESPONSE",
+}
+
+func (t MessageType) String() string {
+	if s, ok := messageTypes[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("TYPE%d", int(t))
+}
+
+// SocketProtocol is the transport a message was received or sent over.
+type SocketProtocol int
+
+// Socket protocols of the dnstap schema.
+const (
+	UDP         SocketProtocol = 1
+	TCP         SocketProtocol = 2
+	DOT         SocketProtocol = 3
+	DOH         SocketProtocol = 4
+	DNSCryptUDP SocketProtocol = 5
+	DNSCryptTCP SocketProtocol = 6
+	DOQ         SocketProtocol = 7
+)
+
+var socketProtocols = map[SocketProtocol]string{
+	UDP:         "udp",
+	TCP:         "tcp",
+	DOT:         "dot",
+	DOH:         "doh",
+	DNSCryptUDP: "dnscrypt-udp",
+	DNSCryptTCP: "dnscrypt-tcp",
+	DOQ:         "doq",
+}
+
+func (p SocketProtocol) String() string {
+	if s, ok := socketProtocols[p]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// Message is a logged DNS message.
+type Message struct {
+	Type            MessageType
+	SocketProtocol  SocketProtocol
+	QueryAddress    net.IP
+	ResponseAddress net.IP
+	QueryTime       time.Time
+	ResponseTime    time.Time
+	// QueryMessage and ResponseMessage are the DNS messages in wire format.
+	QueryMessage    []byte
+	ResponseMessage []byte
+}
+
+// Field numbers of the Dnstap and Message protobuf messages.
+const (
+	dnstapType    protowire.Number = 15
+	dnstapMessage protowire.Number = 14
+
+	dnstapTypeMessage = 1
+
+	messageType             protowire.Number = 1
+	messageSocketProtocol   protowire.Number = 3
+	messageQueryAddress     protowire.Number = 4
+	messageResponseAddress  protowire.Number = 5
+	messageQueryTimeSec     protowire.Number = 8
+	messageQueryTimeNsec    protowire.Number = 9
+	messageQueryMessage     protowire.Number = 10
+	messageResponseTimeSec  protowire.Number = 12
+	messageResponseTimeNsec protowire.Number = 13
+	messageResponseMessage  protowire.Number = 14
+)
+
+// ErrNotMessage is returned for dnstap payloads not containing a message.
+var ErrNotMessage = errors.New("dnstap payload doesn't contain a message")
+
+// Unmarshal decodes a serialized Dnstap protobuf. The returned message
+// references b.
+func Unmarshal(b []byte) (Message, error) {
+	var (
+		typ uint64
+		msg []byte
+	)
+	err := wire.Fields(b, func(num protowire.Number, _ protowire.Type, v uint64, b []byte) {
+		switch num {
+		case dnstapType:
+			typ = v
+		case dnstapMessage:
+			msg = b
+		}
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	if typ != dnstapTypeMessage || msg == nil {
+		return Message{}, ErrNotMessage
+	}
+
+	var (
+		m                        Message
+		qsec, qnsec, rsec, rnsec uint64
+	)
+	err = wire.Fields(msg, func(num protowire.Number, _ protowire.Type, v uint64, b []byte) {
+		switch num {
+		case messageType:
+			m.Type = MessageType(v)
+		case messageSocketProtocol:
+			m.SocketProtocol = SocketProtocol(v)
+		case messageQueryAddress:
+			m.QueryAddress = net.IP(b)
+		case messageResponseAddress:
+			m.ResponseAddress = net.IP(b)
+		case messageQueryTimeSec:
+			qsec = v
+		case messageQueryTimeNsec:
+			qnsec = v
+		case messageQueryMessage:
+			m.QueryMessage = b
+		case messageResponseTimeSec:
+			rsec = v
+		case messageResponseTimeNsec:
+			rnsec = v
+		case messageResponseMessage:
+			m.ResponseMessage = b
+		}
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	if qsec != 0 {
+		m.QueryTime = time.Unix(int64(qsec), int64(qnsec))
+	}
+	if rsec != 0 {
+		m.ResponseTime = time.Unix(int64(rsec), int64(rnsec))
+	}
+	return m, nil
+}
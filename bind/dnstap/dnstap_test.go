@@ -0,0 +1,201 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnstap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dnsMessage returns a DNS message with a question for example.com.
+func dnsMessage(qtype uint16, rcode int) []byte {
+	b := []byte{0x12, 0x34, 0x81, byte(rcode), 0, 1, 0, 0, 0, 0, 0, 0}
+	b = append(b, "\x07example\x03com\x00"...)
+	b = binary.BigEndian.AppendUint16(b, qtype)
+	return binary.BigEndian.AppendUint16(b, 1)
+}
+
+func TestParseHeader(t *testing.T) {
+	h, err := ParseHeader(dnsMessage(28, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != (Header{Rcode: 3, QType: 28}) {
+		t.Errorf("unexpected header %+v", h)
+	}
+	if QTypeName(h.QType) != "AAAA" || RcodeName(h.Rcode) != "NXDOMAIN" {
+		t.Errorf("unexpected names %s %s", QTypeName(h.QType), RcodeName(h.Rcode))
+	}
+
+	if _, err := ParseHeader(dnsMessage(1, 0)[:20]); err == nil {
+		t.Error("expected error for truncated message")
+	}
+	if got := QTypeName(65280); got != "other" {
+		t.Errorf("want other for private type, got %s", got)
+	}
+}
+
+func TestServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnstap.sock")
+	l, err := Listen(path, 0o660, os.Getgid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o660 {
+		t.Errorf("expected socket mode 0660, got %v, %v", fi.Mode(), err)
+	}
+
+	var (
+		mu  sync.Mutex
+		got []Message
+		wg  sync.WaitGroup
+	)
+	wg.Add(2)
+	s := &Server{
+		Handle: func(m Message) {
+			mu.Lock()
+			defer mu.Unlock()
+			// Handle must copy the message to keep it.
+			m.QueryAddress = bytes.Clone(m.QueryAddress)
+			m.ResponseAddress = bytes.Clone(m.ResponseAddress)
+			m.QueryMessage = bytes.Clone(m.QueryMessage)
+			got = append(got, m)
+			wg.Done()
+		},
+		Error: func(err error) { t.Error(err) },
+	}
+	go s.Serve(l)
+
+	conn, err := net.Dial("unix", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	w, err := NewWriter(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qtime := time.Unix(1700000000, 123000000)
+	want := []Message{
+		{
+			Type:           ClientQuery,
+			SocketProtocol: UDP,
+			QueryAddress:   net.IP{192, 0, 2, 1},
+			QueryTime:      qtime,
+			QueryMessage:   dnsMessage(1, 0),
+		},
+		{
+			Type:            ResolverResponse,
+			SocketProtocol:  TCP,
+			ResponseAddress: net.ParseIP("2001:db8::53"),
+			QueryTime:       qtime,
+			ResponseTime:    qtime.Add(25 * time.Millisecond),
+		},
+	}
+	for _, m := range want {
+		if err := w.Write(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	for i := range got {
+		if !got[i].QueryTime.Equal(want[i].QueryTime) || !got[i].ResponseTime.Equal(want[i].ResponseTime) {
+			t.Errorf("unexpected times %v %v", got[i].QueryTime, got[i].ResponseTime)
+		}
+		got[i].QueryTime, got[i].ResponseTime = want[i].QueryTime, want[i].ResponseTime
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected messages:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestReadStreamUnidirectional(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeControl(&buf, control{typ: controlStart, contentTypes: []string{ContentType}}); err != nil {
+		t.Fatal(err)
+	}
+	(&Writer{rw: &buf}).Write(Message{Type: ClientResponse})
+	if err := writeControl(&buf, control{typ: controlStop}); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := readStream(&buf, func(frame []byte) {
+		if m, err := Unmarshal(frame); err != nil || m.Type != ClientResponse {
+			t.Errorf("unexpected message %+v: %v", m, err)
+		}
+		n++
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("want 1 message, got %d", n)
+	}
+}
+
+func TestReadStreamErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeControl(&buf, control{typ: controlReady, contentTypes: []string{"protobuf:other"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := readStream(&buf, func([]byte) {}); err == nil {
+		t.Error("expected error for unsupported content type")
+	}
+
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0, 1, 0})
+	if err := readStream(&buf, func([]byte) {}); err == nil {
+		t.Error("expected error for stream without control frame")
+	}
+}
+
+// failingListener fails to accept connections a number of times before it is
+// closed.
+type failingListener struct {
+	net.Listener
+	failures int
+}
+
+func (l *failingListener) Accept() (net.Conn, error) {
+	if l.failures == 0 {
+		return nil, net.ErrClosed
+	}
+	l.failures--
+	return nil, errors.New("too many open files")
+}
+
+func TestServerAcceptError(t *testing.T) {
+	var errs int
+	s := &Server{Error: func(error) { errs++ }}
+	if err := s.Serve(&failingListener{failures: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if errs != 3 {
+		t.Errorf("expected 3 accept errors, got %d", errs)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnstap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ContentType is the Frame Streams content type of dnstap.
+const ContentType = "protobuf:dnstap.Dnstap"
+
+// Frame Streams control frame types.
+const (
+	controlAccept uint32 = 0x01
+	controlStart  uint32 = 0x02
+	controlStop   uint32 = 0x03
+	controlReady  uint32 = 0x04
+	controlFinish uint32 = 0x05
+
+	controlFieldContentType uint32 = 0x01
+)
+
+// maxFrameSize bounds the size of a single frame.
+const maxFrameSize = 1 << 20
+
+// control is a Frame Streams control frame.
+type control struct {
+	typ          uint32
+	contentTypes []string
+}
+
+func (c control) marshal() []byte {
+	b := binary.BigEndian.AppendUint32(nil, c.typ)
+	for _, t := range c.contentTypes {
+		b = binary.BigEndian.AppendUint32(b, controlFieldContentType)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(t)))
+		b = append(b, t...)
+	}
+	return b
+}
+
+func unmarshalControl(b []byte) (control, error) {
+	if len(b) < 4 {
+		return control{}, errors.New("short control frame")
+	}
+	c := control{typ: binary.BigEndian.Uint32(b)}
+	b = b[4:]
+	for len(b) > 0 {
+		if len(b) < 8 {
+			return c, errors.New("short control field")
+		}
+		typ, n := binary.BigEndian.Uint32(b), binary.BigEndian.Uint32(b[4:])
+		b = b[8:]
+		if uint32(len(b)) < n {
+			return c, errors.New("short control field")
+		}
+		if typ == controlFieldContentType {
+			c.contentTypes = append(c.contentTypes, string(b[:n]))
+		}
+		b = b[n:]
+	}
+	return c, nil
+}
+
+func (c control) accepts(contentType string) bool {
+	if len(c.contentTypes) == 0 {
+		return true
+	}
+	for _, t := range c.contentTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// readFrame reads a data frame or, if the returned control is non-nil, a
+// control frame.
+func readFrame(r io.Reader, buf []byte) ([]byte, *control, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:])
+	isControl := n == 0
+	if isControl {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, nil, unexpectedEOF(err)
+		}
+		n = binary.BigEndian.Uint32(hdr[:])
+	}
+	if n > maxFrameSize {
+		return nil, nil, fmt.Errorf("frame of %d bytes exceeds maximum size", n)
+	}
+	if cap(buf) < int(n) {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, nil, unexpectedEOF(err)
+	}
+	if !isControl {
+		return buf, nil, nil
+	}
+	c, err := unmarshalControl(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &c, nil
+}
+
+func writeControl(w io.Writer, c control) error {
+	b := c.marshal()
+	frame := make([]byte, 8, 8+len(b))
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(b)))
+	_, err := w.Write(append(frame, b...))
+	return err
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readStream reads a Frame Streams stream of dnstap frames from rw and passes
+// the data frames to fn. Both the bidirectional handshake, which named uses
+// on sockets, and unidirectional streams are supported. The frame passed to
+// fn is only valid until fn returns.
+func readStream(rw io.ReadWriter, fn func([]byte)) error {
+	r := bufio.NewReader(rw)
+	var buf []byte
+
+	_, c, err := readFrame(r, buf)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return errors.New("stream doesn't start with a control frame")
+	}
+	if c.typ == controlReady {
+		if !c.accepts(ContentType) {
+			return fmt.Errorf("unsupported content types %q", c.contentTypes)
+		}
+		if err := writeControl(rw, control{typ: controlAccept, contentTypes: []string{ContentType}}); err != nil {
+			return err
+		}
+		if _, c, err = readFrame(r, buf); err != nil {
+			return unexpectedEOF(err)
+		}
+	}
+	if c == nil || c.typ != controlStart {
+		return errors.New("expected start frame")
+	}
+	if !c.accepts(ContentType) {
+		return fmt.Errorf("unsupported content types %q", c.contentTypes)
+	}
+
+	for {
+		frame, c, err := readFrame(r, buf)
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if c == nil {
+			fn(frame)
+			buf = frame
+			continue
+		}
+		if c.typ != controlStop {
+			return fmt.Errorf("unexpected control frame %d", c.typ)
+		}
+		// A unidirectional writer doesn't read the finish frame, the error
+		// of writing it is therefore ignored.
+		_ = writeControl(rw, control{typ: controlFinish})
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnstap
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// Server accepts Frame Streams connections from named.
+type Server struct {
+	// Handle is called for every message received. It may be called
+	// concurrently for messages of different connections. The message
+	// references memory reused once Handle returns.
+	Handle func(Message)
+	// Error is called for connections which failed or couldn't be accepted
+	// and messages which couldn't be decoded.
+	Error func(error)
+}
+
+// Listen removes a stale socket at path and listens on it. The socket is
+// created according to the umask, unless mode is not zero. Its group is
+// changed to gid unless it is -1. named needs write permission to connect.
+func Listen(path string, mode os.FileMode, gid int) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if gid != -1 {
+		if err := os.Chown(path, -1, gid); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Serve accepts connections on l until it is closed. Errors accepting
+// connections, e.g. running out of file descriptors, are passed to Error and
+// retried with a backoff.
+func (s *Server) Serve(l net.Listener) error {
+	var backoff time.Duration
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			if s.Error != nil {
+				s.Error(err)
+			}
+			backoff = min(max(2*backoff, 5*time.Millisecond), time.Second)
+			time.Sleep(backoff)
+			continue
+		}
+		backoff = 0
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	err := readStream(conn, func(frame []byte) {
+		m, err := Unmarshal(frame)
+		if err != nil {
+			if err != ErrNotMessage {
+				s.error(err)
+			}
+			return
+		}
+		s.Handle(m)
+	})
+	if err != nil {
+		s.error(err)
+	}
+}
+
+func (s *Server) error(err error) {
+	if s.Error != nil {
+		s.Error(err)
+	}
+}
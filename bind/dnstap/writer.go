@@ -0,0 +1,111 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnstap
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Marshal serializes a message as Dnstap protobuf.
+func Marshal(m Message) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, messageType, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(m.Type))
+	if m.SocketProtocol != 0 {
+		b = protowire.AppendTag(b, messageSocketProtocol, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.SocketProtocol))
+	}
+	for _, f := range []struct {
+		num protowire.Number
+		b   []byte
+	}{
+		{messageQueryAddress, m.QueryAddress},
+		{messageResponseAddress, m.ResponseAddress},
+		{messageQueryMessage, m.QueryMessage},
+		{messageResponseMessage, m.ResponseMessage},
+	} {
+		if f.b != nil {
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendBytes(b, f.b)
+		}
+	}
+	if !m.QueryTime.IsZero() {
+		b = protowire.AppendTag(b, messageQueryTimeSec, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.QueryTime.Unix()))
+		b = protowire.AppendTag(b, messageQueryTimeNsec, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, uint32(m.QueryTime.Nanosecond()))
+	}
+	if !m.ResponseTime.IsZero() {
+		b = protowire.AppendTag(b, messageResponseTimeSec, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.ResponseTime.Unix()))
+		b = protowire.AppendTag(b, messageResponseTimeNsec, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, uint32(m.ResponseTime.Nanosecond()))
+	}
+
+	var d []byte
+	d = protowire.AppendTag(d, dnstapType, protowire.VarintType)
+	d = protowire.AppendVarint(d, dnstapTypeMessage)
+	d = protowire.AppendTag(d, dnstapMessage, protowire.BytesType)
+	return protowire.AppendBytes(d, b)
+}
+
+// Writer writes messages as a bidirectional Frame Streams stream, the way
+// named does. It is mostly useful for testing.
+type Writer struct {
+	rw io.ReadWriter
+}
+
+// NewWriter performs the Frame Streams handshake on rw.
+func NewWriter(rw io.ReadWriter) (*Writer, error) {
+	if err := writeControl(rw, control{typ: controlReady, contentTypes: []string{ContentType}}); err != nil {
+		return nil, err
+	}
+	_, c, err := readFrame(rw, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.typ != controlAccept {
+		return nil, errors.New("expected accept frame")
+	}
+	if err := writeControl(rw, control{typ: controlStart, contentTypes: []string{ContentType}}); err != nil {
+		return nil, err
+	}
+	return &Writer{rw: rw}, nil
+}
+
+// Write writes a message as data frame.
+func (w *Writer) Write(m Message) error {
+	b := Marshal(m)
+	_, err := w.rw.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...))
+	return err
+}
+
+// Close stops the stream and waits for the reader to finish it.
+func (w *Writer) Close() error {
+	if err := writeControl(w.rw, control{typ: controlStop}); err != nil {
+		return err
+	}
+	_, c, err := readFrame(w.rw, nil)
+	if err != nil {
+		return err
+	}
+	if c == nil || c.typ != controlFinish {
+		return errors.New("expected finish frame")
+	}
+	return nil
+}
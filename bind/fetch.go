@@ -0,0 +1,117 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"bufio"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrResponseTooLarge is returned when a statistics document exceeds the
+// configured maximum response size.
+var ErrResponseTooLarge = errors.New("response exceeds maximum size")
+
+// ErrNotFound is returned when the server doesn't serve the requested
+// statistics document, e.g. because it predates or removed it.
+var ErrNotFound = errors.New("statistics document not found")
+
+var (
+	bufPool  = sync.Pool{New: func() interface{} { return bufio.NewReaderSize(nil, 32*1024) }}
+	zlibPool sync.Pool
+)
+
+// Fetch queries the given URL and passes the response body to decode.
+// Responses compressed with deflate, the only encoding BIND supports, are
+// requested and transparently decompressed. If maxSize is positive, reading
+// more than maxSize bytes of the decompressed body fails with
+// ErrResponseTooLarge. The reader passed to decode is only valid until
+// decode returns.
+func Fetch(c *http.Client, u string, maxSize int64, decode func(io.Reader) error) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %s", u, err)
+	}
+	// net/http only decompresses gzip transparently, the body is
+	// decompressed below.
+	req.Header.Set("Accept-Encoding", "deflate")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("error querying stats: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status for %q: %s", u, resp.Status)
+	}
+
+	var r io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "deflate" {
+		var zr io.ReadCloser
+		if v := zlibPool.Get(); v != nil {
+			zr = v.(io.ReadCloser)
+			err = zr.(zlib.Resetter).Reset(r, nil)
+		} else {
+			zr, err = zlib.NewReader(r)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decompress response: %s", err)
+		}
+		defer zlibPool.Put(zr)
+		r = zr
+	} else if maxSize > 0 && resp.ContentLength > maxSize {
+		return ErrResponseTooLarge
+	}
+	if maxSize > 0 {
+		r = &limitedReader{r: r, n: maxSize}
+	}
+
+	buf := bufPool.Get().(*bufio.Reader)
+	buf.Reset(r)
+	defer func() {
+		buf.Reset(nil)
+		bufPool.Put(buf)
+	}()
+
+	return decode(buf)
+}
+
+// limitedReader reads from r and fails once more than n bytes have been read.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package file implements a bind.Client reading statistics documents saved
+// from BIND's statistics channel.
+package file
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+// Stdin is the path which makes a Client read a single document from the
+// standard input.
+const Stdin = "-"
+
+// Client implements bind.Client and reads XML v3 or JSON v1 statistics from
+// the local filesystem. The format is detected from the content of the
+// documents.
+//
+// If the path is a directory, documents are read from files laid out like the
+// HTTP paths of the statistics channel, e.g. <path>/json/v1/server. Otherwise
+// the file, or the standard input for Stdin, has to contain a complete
+// statistics document as returned by /json/v1 or /xml/v3, which is used for
+// all statistic groups. The standard input is only read once.
+type Client struct {
+	path string
+
+	stdinOnce sync.Once
+	stdin     []byte
+	stdinErr  error
+}
+
+// NewClient returns an initialized Client.
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// PathFromURL returns the path of a file:// URL for NewClient. The host has
+// to be empty or localhost, as the documents can only be read locally. An
+// opaque URL like file:- or file:stats.xml is a path relative to the working
+// directory, or Stdin.
+func PathFromURL(u *url.URL) (string, error) {
+	if u.Opaque != "" {
+		return u.Opaque, nil
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		return "", fmt.Errorf("unsupported host %q in file URL %q, expected file:///path", u.Host, u)
+	}
+	return u.Path, nil
+}
+
+// Stats implements bind.Stats.
+func (c *Client) Stats(groups ...bind.StatisticGroup) (bind.Statistics, error) {
+	client, err := c.client()
+	if err != nil {
+		return bind.Statistics{}, err
+	}
+	return client.Stats(groups...)
+}
+
+// client returns a client for the format of the saved documents.
+func (c *Client) client() (bind.Client, error) {
+	hc := &http.Client{Transport: c}
+	candidates := []string{
+		json.ServerPath, json.ZonesPath, json.TasksPath,
+		xml.ServerPath, xml.ZonesPath, xml.TasksPath,
+	}
+	for _, p := range candidates {
+		b, err := c.read(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch sniff(b) {
+		case '{':
+			return json.NewClient("file:///", hc), nil
+		case '<':
+			return xml.NewClient("file:///", hc), nil
+		default:
+			return nil, fmt.Errorf("unknown statistics format in %s", c.name(p))
+		}
+	}
+	return nil, fmt.Errorf("no statistics documents found in %s", c.path)
+}
+
+// RoundTrip implements http.RoundTripper by serving the document for the path
+// of the request.
+func (c *Client) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp := &http.Response{
+		Proto:      "HTTP/1.0",
+		ProtoMajor: 1,
+		Header:     http.Header{},
+		Request:    r,
+	}
+
+	b, err := c.read(r.URL.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		resp.StatusCode = http.StatusNotFound
+		b = nil
+	case err != nil:
+		return nil, err
+	default:
+		resp.StatusCode = http.StatusOK
+	}
+	resp.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	resp.ContentLength = int64(len(b))
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return resp, nil
+}
+
+// read returns the document for the given HTTP path.
+func (c *Client) read(p string) ([]byte, error) {
+	if c.path == Stdin {
+		c.stdinOnce.Do(func() {
+			c.stdin, c.stdinErr = io.ReadAll(os.Stdin)
+		})
+		return c.stdin, c.stdinErr
+	}
+	return os.ReadFile(c.name(p))
+}
+
+// name returns the file name of the document for the given HTTP path.
+func (c *Client) name(p string) string {
+	if fi, err := os.Stat(c.path); err == nil && fi.IsDir() {
+		return filepath.Join(c.path, filepath.FromSlash(strings.TrimPrefix(p, "/")))
+	}
+	return c.path
+}
+
+// sniff returns the first significant character of a document.
+func sniff(b []byte) byte {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	b = bytes.TrimLeft(b, " \t\r\n")
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+)
+
+// statsDir returns a directory laid out like the HTTP paths of the statistics
+// channel with the given documents.
+func statsDir(t *testing.T, docs map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, f := range docs {
+		b, err := os.ReadFile(filepath.Join("../../fixtures", f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(p)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, p), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestPathFromURL(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want string
+		err  bool
+	}{
+		{url: "file:///var/lib/bind-stats", want: "/var/lib/bind-stats"},
+		{url: "file://localhost/var/lib/bind-stats", want: "/var/lib/bind-stats"},
+		{url: "file://LOCALHOST/stats.xml", want: "/stats.xml"},
+		{url: "file:stats.xml", want: "stats.xml"},
+		{url: "file:-", want: Stdin},
+		{url: "file://ns1.example.com/var/lib/bind-stats", err: true},
+		{url: "file://localhost:8053/stats.xml", err: true},
+	} {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := PathFromURL(u)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got %q", tc.url, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tc.url, err)
+		} else if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	root := statsDir(t, map[string]string{
+		"json/v1/server": "json/server.json",
+		"json/v1/tasks":  "json/tasks.json",
+	})
+	for name, path := range map[string]string{
+		"directory": root,
+		// A single document is used for all groups.
+		"file": "../../fixtures/xml/server.xml",
+	} {
+		s, err := NewClient(path).Stats(bind.ServerStats, bind.ViewStats)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if s.Version == "" || len(s.Server.IncomingRequests) == 0 || len(s.Views) == 0 {
+			t.Errorf("%s: expected server and view statistics, got %+v", name, s)
+		}
+	}
+
+	if _, err := NewClient(filepath.Join(root, "missing")).Stats(bind.ServerStats); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if _, err := NewClient(t.TempDir()).Stats(bind.ServerStats); err == nil {
+		t.Error("expected error for directory without documents")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := NewClient(statsDir(t, map[string]string{"json/v1/server": "json/server.json"}))
+	for path, want := range map[string]int{
+		json.ServerPath: http.StatusOK,
+		json.ZonesPath:  http.StatusNotFound,
+	} {
+		req, err := http.NewRequest(http.MethodGet, "file://"+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			t.Errorf("%s: %s", path, err)
+			continue
+		}
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("%s: got status %d, want %d", path, resp.StatusCode, want)
+		}
+		if (want == http.StatusOK) != (len(b) > 0) || resp.ContentLength != int64(len(b)) {
+			t.Errorf("%s: got %d bytes with content length %d", path, len(b), resp.ContentLength)
+		}
+	}
+}
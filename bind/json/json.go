@@ -15,11 +15,12 @@ package json
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
-	"strconv"
 	"time"
 
 	"github.com/prometheus-community/bind_exporter/bind"
@@ -32,11 +33,20 @@ const (
 	TasksPath = "/json/v1/tasks"
 	// ZonesPath is the HTTP path of the JSON v1 zones resource.
 	ZonesPath = "/json/v1/zones"
+	// XfrinsPath is the HTTP path of the JSON v1 incoming zone transfers
+	// resource.
+	XfrinsPath = "/json/v1/xfrins"
+	// NetPath is the HTTP path of the JSON v1 network resource.
+	NetPath = "/json/v1/net"
 )
 
 type Gauges map[string]uint64
 type Counters map[string]uint64
 
+// Statistics is the server resource of the JSON v1 API.
+//
+// Deprecated: Client decodes the statistics documents while they are read
+// and no longer uses this type. It is kept for compatibility.
 type Statistics struct {
 	BootTime   time.Time `json:"boot-time"`
 	ConfigTime time.Time `json:"config-time"`
@@ -45,6 +55,7 @@ type Statistics struct {
 	NSStats    Counters  `json:"nsstats"`
 	Rcodes     Counters  `json:"rcodes"`
 	ZoneStats  Counters  `json:"zonestats"`
+	SockStats  Counters  `json:"sockstats"`
 	Views      map[string]struct {
 		Resolver struct {
 			Cache  Gauges   `json:"cache"`
@@ -54,6 +65,9 @@ type Statistics struct {
 	} `json:"views"`
 }
 
+// ZoneStatistics is the zones resource of the JSON v1 API.
+//
+// Deprecated: Client no longer uses this type. It is kept for compatibility.
 type ZoneStatistics struct {
 	Views map[string]struct {
 		Zones []struct {
@@ -64,6 +78,9 @@ type ZoneStatistics struct {
 	} `json:"views"`
 }
 
+// TaskStatistics is the tasks resource of the JSON v1 API.
+//
+// Deprecated: Client no longer uses this type. It is kept for compatibility.
 type TaskStatistics struct {
 	TaskMgr struct {
 		TasksRunning  uint64 `json:"tasks-running"`
@@ -75,6 +92,10 @@ type TaskStatistics struct {
 type Client struct {
 	url  string
 	http *http.Client
+
+	// MaxResponseSize limits the size of a single statistics document. Zero
+	// means no limit.
+	MaxResponseSize int64
 }
 
 // NewClient returns an initialized Client.
@@ -89,27 +110,25 @@ func NewClient(url string, c *http.Client) *Client {
 // v. The endpoint must return a valid JSON representation which can be
 // unmarshaled into the provided value.
 func (c *Client) Get(p string, v interface{}) error {
+	return c.stream(p, func(d *json.Decoder) error {
+		return d.Decode(v)
+	})
+}
+
+// stream queries the given path and passes a decoder for the response to fn.
+func (c *Client) stream(p string, fn func(*json.Decoder) error) error {
 	u, err := url.Parse(c.url)
 	if err != nil {
 		return fmt.Errorf("invalid URL %q: %s", c.url, err)
 	}
 	u.Path = path.Join(u.Path, p)
 
-	resp, err := c.http.Get(u.String())
-	if err != nil {
-		return fmt.Errorf("error querying stats: %s", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status for %q: %s", u, resp.Status)
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON response: %s", err)
-	}
-
-	return nil
+	return bind.Fetch(c.http, u.String(), c.MaxResponseSize, func(r io.Reader) error {
+		if err := fn(json.NewDecoder(r)); err != nil {
+			return fmt.Errorf("failed to unmarshal JSON response: %s", err)
+		}
+		return nil
+	})
 }
 
 // Stats implements bind.Stats.
@@ -121,75 +140,351 @@ func (c *Client) Stats(groups ...bind.StatisticGroup) (bind.Statistics, error) {
 	}
 
 	if m[bind.ServerStats] || m[bind.ViewStats] {
-		var stats Statistics
-		if err := c.Get(ServerPath, &stats); err != nil {
+		if err := c.stream(ServerPath, func(d *json.Decoder) error {
+			return object(d, header(d, &s, func(key string) error {
+				if m[bind.ServerStats] {
+					switch key {
+					case "boot-time":
+						return d.Decode(&s.Server.BootTime)
+					case "config-time":
+						return d.Decode(&s.Server.ConfigTime)
+					case "opcodes":
+						return decodeCounters(d, &s.Server.IncomingRequests)
+					case "qtypes":
+						return decodeCounters(d, &s.Server.IncomingQueries)
+					case "nsstats":
+						return decodeCounters(d, &s.Server.NameServerStats)
+					case "rcodes":
+						return decodeCounters(d, &s.Server.ServerRcodes)
+					case "zonestats":
+						return decodeCounters(d, &s.Server.ZoneStatistics)
+					case "sockstats":
+						return decodeCounters(d, &s.Server.SocketStatistics)
+					}
+				}
+				if m[bind.ViewStats] && key == "views" {
+					return object(d, func(name string) error {
+						v, err := decodeView(d, name)
+						s.Views = append(s.Views, v)
+						return err
+					})
+				}
+				return skip(d)
+			}))
+		}); err != nil {
+			return s, err
+		}
+	}
+
+	if m[bind.ZoneStats] {
+		if err := c.stream(ZonesPath, func(d *json.Decoder) error {
+			return object(d, header(d, &s, func(key string) error {
+				if key != "views" {
+					return skip(d)
+				}
+				return object(d, func(name string) error {
+					v, err := decodeZoneView(d, name)
+					s.ZoneViews = append(s.ZoneViews, v)
+					return err
+				})
+			}))
+		}); err != nil {
 			return s, err
 		}
+	}
+
+	if m[bind.TransferStats] {
+		err := c.stream(XfrinsPath, func(d *json.Decoder) error {
+			return object(d, header(d, &s, func(key string) error {
+				if key != "views" {
+					return skip(d)
+				}
+				return object(d, func(name string) error {
+					v, err := decodeTransferView(d, name)
+					s.TransferViews = append(s.TransferViews, v)
+					return err
+				})
+			}))
+		})
+		if errors.Is(err, bind.ErrNotFound) {
+			s.Unsupported = append(s.Unsupported, bind.TransferStats)
+		} else if err != nil {
+			return s, err
+		}
+	}
+
+	if m[bind.ListenerStats] {
+		found := false
+		err := c.stream(NetPath, func(d *json.Decoder) error {
+			return object(d, header(d, &s, func(key string) error {
+				if key != "socketmgr" {
+					return skip(d)
+				}
+				found = true
+				return object(d, func(key string) error {
+					if key != "sockets" {
+						return skip(d)
+					}
+					return array(d, func() error {
+						var sock struct {
+							ID           string   `json:"id"`
+							References   uint64   `json:"references"`
+							Type         string   `json:"type"`
+							LocalAddress string   `json:"local-address"`
+							States       []string `json:"states"`
+						}
+						if err := d.Decode(&sock); err != nil {
+							return err
+						}
+						s.Sockets = append(s.Sockets, bind.Socket{
+							ID:           sock.ID,
+							Type:         sock.Type,
+							LocalAddress: sock.LocalAddress,
+							References:   sock.References,
+							States:       sock.States,
+						})
+						return nil
+					})
+				})
+			}))
+		})
+		switch {
+		case errors.Is(err, bind.ErrNotFound):
+			s.Unsupported = append(s.Unsupported, bind.ListenerStats)
+		case err != nil:
+			return s, err
+		case !found:
+			s.Unsupported = append(s.Unsupported, bind.ListenerStats)
+		}
+	}
+
+	if m[bind.TaskStats] {
+		err := c.stream(TasksPath, func(d *json.Decoder) error {
+			return object(d, header(d, &s, func(key string) error {
+				switch key {
+				case "taskmgr":
+					s.TaskManager = &bind.TaskManager{}
+					return object(d, func(key string) error {
+						switch key {
+						case "tasks-running":
+							return d.Decode(&s.TaskManager.ThreadModel.TasksRunning)
+						case "worker-threads":
+							return d.Decode(&s.TaskManager.ThreadModel.WorkerThreads)
+						}
+						return skip(d)
+					})
+				case "loopmgr":
+					s.LoopManager = &bind.LoopManager{}
+					return object(d, func(key string) error {
+						if key == "loops" {
+							return d.Decode(&s.LoopManager.Loops)
+						}
+						return skip(d)
+					})
+				}
+				return skip(d)
+			}))
+		})
+		switch {
+		case errors.Is(err, bind.ErrNotFound):
+			s.Unsupported = append(s.Unsupported, bind.TaskStats)
+		case err != nil:
+			return s, err
+		case s.TaskManager == nil && s.LoopManager == nil:
+			s.Unsupported = append(s.Unsupported, bind.TaskStats)
+		}
+	}
+
+	return s, nil
+}
+
+// header returns an object callback decoding the version fields found at the
+// top of every statistics document into s and passing all other keys to fn.
+func header(d *json.Decoder, s *bind.Statistics, fn func(string) error) func(string) error {
+	return func(key string) error {
+		switch key {
+		case "version":
+			return d.Decode(&s.Version)
+		case "json-stats-version":
+			return d.Decode(&s.SchemaVersion)
+		}
+		return fn(key)
+	}
+}
 
-		s.Server.BootTime = stats.BootTime
-		s.Server.ConfigTime = stats.ConfigTime
+func decodeCounters(d *json.Decoder, c *[]bind.Counter) error {
+	var counters Counters
+	if err := d.Decode(&counters); err != nil {
+		return err
+	}
+	for k, val := range counters {
+		*c = append(*c, bind.Counter{Name: k, Counter: val})
+	}
+	return nil
+}
 
-		for k, val := range stats.Opcodes {
-			s.Server.IncomingRequests = append(s.Server.IncomingRequests, bind.Counter{Name: k, Counter: val})
+func decodeView(d *json.Decoder, name string) (bind.View, error) {
+	v := bind.View{Name: name}
+	err := object(d, func(key string) error {
+		if key != "resolver" {
+			return skip(d)
+		}
+		var resolver struct {
+			Cache  Gauges   `json:"cache"`
+			Qtypes Counters `json:"qtypes"`
+			Stats  Counters `json:"stats"`
 		}
-		for k, val := range stats.QTypes {
-			s.Server.IncomingQueries = append(s.Server.IncomingQueries, bind.Counter{Name: k, Counter: val})
+		if err := d.Decode(&resolver); err != nil {
+			return err
 		}
-		for k, val := range stats.NSStats {
-			s.Server.NameServerStats = append(s.Server.NameServerStats, bind.Counter{Name: k, Counter: val})
+		for k, val := range resolver.Cache {
+			v.Cache = append(v.Cache, bind.Gauge{Name: k, Gauge: val})
 		}
-		for k, val := range stats.Rcodes {
-			s.Server.ServerRcodes = append(s.Server.ServerRcodes, bind.Counter{Name: k, Counter: val})
+		for k, val := range resolver.Qtypes {
+			v.ResolverQueries = append(v.ResolverQueries, bind.Counter{Name: k, Counter: val})
 		}
-		for k, val := range stats.ZoneStats {
-			s.Server.ZoneStatistics = append(s.Server.ZoneStatistics, bind.Counter{Name: k, Counter: val})
+		for k, val := range resolver.Stats {
+			v.ResolverStats = append(v.ResolverStats, bind.Counter{Name: k, Counter: val})
 		}
+		return nil
+	})
+	return v, err
+}
 
-		for name, view := range stats.Views {
-			v := bind.View{Name: name}
-			for k, val := range view.Resolver.Cache {
-				v.Cache = append(v.Cache, bind.Gauge{Name: k, Gauge: val})
+func decodeZoneView(d *json.Decoder, name string) (bind.ZoneView, error) {
+	v := bind.ZoneView{Name: name}
+	err := object(d, func(key string) error {
+		if key != "zones" {
+			return skip(d)
+		}
+		return array(d, func() error {
+			var zone struct {
+				Name          string      `json:"name"`
+				Class         string      `json:"class"`
+				Serial        json.Number `json:"serial"` // -1 for zones which failed to load
+				DNSSECSign    Counters    `json:"dnssec-sign"`
+				DNSSECRefresh Counters    `json:"dnssec-refresh"`
 			}
-			for k, val := range view.Resolver.Qtypes {
-				v.ResolverQueries = append(v.ResolverQueries, bind.Counter{Name: k, Counter: val})
+			if err := d.Decode(&zone); err != nil {
+				return err
 			}
-			for k, val := range view.Resolver.Stats {
-				v.ResolverStats = append(v.ResolverStats, bind.Counter{Name: k, Counter: val})
+			if zone.Class == "IN" {
+				z := bind.ZoneCounter{
+					Name:   zone.Name,
+					Serial: zone.Serial.String(),
+				}
+				for k, val := range zone.DNSSECSign {
+					z.DNSSECSign = append(z.DNSSECSign, bind.Counter{Name: k, Counter: val})
+				}
+				for k, val := range zone.DNSSECRefresh {
+					z.DNSSECRefresh = append(z.DNSSECRefresh, bind.Counter{Name: k, Counter: val})
+				}
+				v.ZoneData = append(v.ZoneData, z)
 			}
-			s.Views = append(s.Views, v)
-		}
-	}
-
-	var zonestats ZoneStatistics
-	if err := c.Get(ZonesPath, &zonestats); err != nil {
-		return s, err
-	}
+			return nil
+		})
+	})
+	return v, err
+}
 
-	for name, view := range zonestats.Views {
-		v := bind.ZoneView{
-			Name: name,
+func decodeTransferView(d *json.Decoder, name string) (bind.TransferView, error) {
+	v := bind.TransferView{Name: name}
+	err := object(d, func(key string) error {
+		if key != "xfrins" {
+			return skip(d)
 		}
-		for _, zone := range view.Zones {
-			if zone.Class != "IN" {
-				continue
+		return array(d, func() error {
+			var xfr struct {
+				Name       string  `json:"name"`
+				Class      string  `json:"class"`
+				State      string  `json:"state"`
+				RemoteAddr string  `json:"remoteaddr"`
+				XfrType    string  `json:"xfrtype"`
+				Duration   float64 `json:"duration"` // seconds
+				NMsg       uint64  `json:"nmsg"`
+				NRecs      uint64  `json:"nrecs"`
+				NBytes     uint64  `json:"nbytes"`
 			}
-			z := bind.ZoneCounter{
-				Name:   zone.Name,
-				Serial: strconv.FormatUint(uint64(zone.Serial), 10),
+			if err := d.Decode(&xfr); err != nil {
+				return err
 			}
-			v.ZoneData = append(v.ZoneData, z)
+			if xfr.Class == "IN" {
+				v.Transfers = append(v.Transfers, bind.Transfer{
+					Zone:     xfr.Name,
+					Primary:  xfr.RemoteAddr,
+					State:    xfr.State,
+					Type:     xfr.XfrType,
+					Duration: time.Duration(xfr.Duration * float64(time.Second)),
+					Messages: xfr.NMsg,
+					Records:  xfr.NRecs,
+					Bytes:    xfr.NBytes,
+				})
+			}
+			return nil
+		})
+	})
+	return v, err
+}
+
+// object reads a JSON object from d and calls fn for each of its keys. fn must
+// consume the value of the member.
+func object(d *json.Decoder, fn func(key string) error) error {
+	if err := delim(d, '{'); err != nil {
+		return err
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(t.(string)); err != nil {
+			return err
 		}
-		s.ZoneViews = append(s.ZoneViews, v)
 	}
+	return delim(d, '}')
+}
 
-	if m[bind.TaskStats] {
-		var taskstats TaskStatistics
-		if err := c.Get(TasksPath, &taskstats); err != nil {
-			return s, err
+// array reads a JSON array from d and calls fn for each of its elements. fn
+// must consume the element.
+func array(d *json.Decoder, fn func() error) error {
+	if err := delim(d, '['); err != nil {
+		return err
+	}
+	for d.More() {
+		if err := fn(); err != nil {
+			return err
 		}
-		s.TaskManager.ThreadModel.TasksRunning = taskstats.TaskMgr.TasksRunning
-		s.TaskManager.ThreadModel.WorkerThreads = taskstats.TaskMgr.WorkerThreads
 	}
+	return delim(d, ']')
+}
 
-	return s, nil
+func delim(d *json.Decoder, want json.Delim) error {
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if t != want {
+		return fmt.Errorf("expected %q, got %v", want, t)
+	}
+	return nil
+}
+
+// skip consumes the next value from d without decoding it.
+func skip(d *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
 }
@@ -0,0 +1,429 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package namedconf parses the configuration file of named.
+//
+// The parser only knows the generic grammar of the file: a statement is a
+// list of words optionally followed by a block of statements and terminated
+// by a semicolon. Include statements are resolved while parsing.
+package namedconf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultPath is the usual location of named.conf.
+const DefaultPath = "/etc/bind/named.conf"
+
+// Statement is a single configuration statement, e.g.
+//
+//	zone "example.com" in { type primary; };
+//
+// has the name "zone", the arguments "example.com" and "in" and a block
+// containing the statement "type".
+type Statement struct {
+	Name  string
+	Args  []string
+	Block Block
+}
+
+// Arg returns the i-th argument or an empty string.
+func (s Statement) Arg(i int) string {
+	if i < len(s.Args) {
+		return s.Args[i]
+	}
+	return ""
+}
+
+// Block is a list of statements.
+type Block []Statement
+
+// Get returns the first statement with the given name.
+func (b Block) Get(name string) (Statement, bool) {
+	for _, s := range b {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Statement{}, false
+}
+
+// All returns all statements with the given name.
+func (b Block) All(name string) []Statement {
+	var ss []Statement
+	for _, s := range b {
+		if s.Name == name {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
+// Uint returns the first argument of the named statement as an integer.
+func (b Block) Uint(name string) (uint64, bool) {
+	s, ok := b.Get(name)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s.Arg(0), 10, 64)
+	return v, err == nil
+}
+
+// Config is a parsed named.conf.
+type Config struct {
+	Block
+	// Files holds the state of the files read by ParseFile, named.conf and
+	// its includes, when they were opened.
+	Files map[string]os.FileInfo
+}
+
+// ParseFile parses the configuration file at path. Relative include paths
+// are resolved against the directory of path.
+func ParseFile(path string) (*Config, error) {
+	p := parser{dir: filepath.Dir(path), files: map[string]os.FileInfo{}}
+	b, err := p.file(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{Block: b, Files: p.files}, nil
+}
+
+// Parse parses the configuration read from r. Relative include paths are
+// resolved against the working directory.
+func Parse(r io.Reader) (*Config, error) {
+	p := parser{}
+	b, err := p.block(newLexer(r, "-"), false)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{Block: b}, nil
+}
+
+// Options returns the global options block.
+func (c *Config) Options() Block {
+	s, _ := c.Get("options")
+	return s.Block
+}
+
+// Limits are the client quotas of the server.
+type Limits struct {
+	// RecursiveClients is the hard limit of concurrent recursive clients.
+	RecursiveClients uint64
+	// TCPClients is the limit of concurrent TCP clients.
+	TCPClients uint64
+}
+
+// Limits returns the client quotas, using the defaults of named for quotas
+// not configured.
+func (c *Config) Limits() Limits {
+	o := c.Options()
+	l := Limits{RecursiveClients: 1000, TCPClients: 150}
+	if v, ok := o.Uint("recursive-clients"); ok {
+		l.RecursiveClients = v
+	}
+	if v, ok := o.Uint("tcp-clients"); ok {
+		l.TCPClients = v
+	}
+	return l
+}
+
+type parser struct {
+	dir   string
+	depth int
+	// files records the files opened if not nil.
+	files map[string]os.FileInfo
+}
+
+func (p *parser) file(path string) (Block, error) {
+	if p.depth > 16 {
+		return nil, fmt.Errorf("%s: includes nested too deeply", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if p.files != nil {
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		p.files[path] = fi
+	}
+
+	p.depth++
+	defer func() { p.depth-- }()
+	return p.block(newLexer(f, path), false)
+}
+
+// block parses statements until the end of the input or, if nested, the
+// closing brace.
+func (p *parser) block(l *lexer, nested bool) (Block, error) {
+	var b Block
+	for {
+		t, err := l.next()
+		if err == io.EOF {
+			if nested {
+				return nil, l.errorf("unexpected end of file")
+			}
+			return b, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.punct('}'):
+			if !nested {
+				return nil, l.errorf("unexpected '}'")
+			}
+			return b, nil
+		case t.punct(';'):
+			continue
+		case t.punct('{'):
+			return nil, l.errorf("unexpected '{'")
+		}
+
+		s, err := p.statement(l, t.text)
+		if err != nil {
+			return nil, err
+		}
+		if s.Name == "include" {
+			inc, err := p.include(l, s)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, inc...)
+			continue
+		}
+		b = append(b, s)
+	}
+}
+
+func (p *parser) statement(l *lexer, name string) (Statement, error) {
+	s := Statement{Name: name}
+	for {
+		t, err := l.next()
+		if err == io.EOF {
+			return s, l.errorf("missing ';' after %q", name)
+		}
+		if err != nil {
+			return s, err
+		}
+		switch {
+		case t.punct(';'):
+			return s, nil
+		case t.punct('}'):
+			return s, l.errorf("missing ';' after %q", name)
+		case t.punct('{'):
+			if s.Block, err = p.block(l, true); err != nil {
+				return s, err
+			}
+			if s.Block == nil {
+				s.Block = Block{}
+			}
+		default:
+			s.Args = append(s.Args, t.text)
+		}
+	}
+}
+
+func (p *parser) include(l *lexer, s Statement) (Block, error) {
+	if len(s.Args) != 1 || s.Block != nil {
+		return nil, l.errorf("invalid include statement")
+	}
+	path := s.Args[0]
+	if !filepath.IsAbs(path) && p.dir != "" {
+		path = filepath.Join(p.dir, path)
+	}
+	return p.file(path)
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func (t token) punct(c byte) bool {
+	return !t.quoted && len(t.text) == 1 && t.text[0] == c
+}
+
+type lexer struct {
+	r    *bufio.Reader
+	name string
+	line int
+}
+
+func newLexer(r io.Reader, name string) *lexer {
+	return &lexer{r: bufio.NewReader(r), name: name, line: 1}
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", l.name, l.line, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) read() (byte, error) {
+	c, err := l.r.ReadByte()
+	if c == '\n' {
+		l.line++
+	}
+	return c, err
+}
+
+func (l *lexer) unread(c byte) {
+	if c == '\n' {
+		l.line--
+	}
+	_ = l.r.UnreadByte()
+}
+
+// next returns the next word, quoted string or one of the punctuation
+// characters '{', '}' and ';', skipping whitespace and comments.
+func (l *lexer) next() (token, error) {
+	for {
+		c, err := l.read()
+		if err != nil {
+			return token{}, err
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '#':
+			if err := l.skipLine(); err != nil {
+				return token{}, err
+			}
+			continue
+		case c == '/':
+			n, err := l.read()
+			if err == nil && n == '/' {
+				if err := l.skipLine(); err != nil {
+					return token{}, err
+				}
+				continue
+			}
+			if err == nil && n == '*' {
+				if err := l.skipComment(); err != nil {
+					return token{}, err
+				}
+				continue
+			}
+			if err == nil {
+				l.unread(n)
+			}
+			return l.word(c)
+		case c == '{' || c == '}' || c == ';':
+			return token{text: string(c)}, nil
+		case c == '"':
+			return l.quoted()
+		default:
+			return l.word(c)
+		}
+	}
+}
+
+func (l *lexer) skipLine() error {
+	for {
+		c, err := l.read()
+		if err != nil || c == '\n' {
+			return err
+		}
+	}
+}
+
+func (l *lexer) skipComment() error {
+	var prev byte
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			return l.errorf("unterminated comment")
+		}
+		if err != nil {
+			return err
+		}
+		if prev == '*' && c == '/' {
+			return nil
+		}
+		prev = c
+	}
+}
+
+func (l *lexer) quoted() (token, error) {
+	var sb strings.Builder
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			return token{}, l.errorf("unterminated string")
+		}
+		if err != nil {
+			return token{}, err
+		}
+		switch c {
+		case '"':
+			return token{text: sb.String(), quoted: true}, nil
+		case '\\':
+			if c, err = l.read(); err != nil {
+				return token{}, l.errorf("unterminated string")
+			}
+		}
+		sb.WriteByte(c)
+	}
+}
+
+func (l *lexer) word(first byte) (token, error) {
+	var sb strings.Builder
+	sb.WriteByte(first)
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			return token{text: sb.String()}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n', '{', '}', ';', '"', '#':
+			l.unread(c)
+			return token{text: sb.String()}, nil
+		}
+		sb.WriteByte(c)
+	}
+}
+
+// Key is a TSIG key statement.
+type Key struct {
+	Name      string
+	Algorithm string
+	// Secret is the base64 encoded secret.
+	Secret string
+}
+
+// Keys returns the global key statements by name.
+func (c *Config) Keys() map[string]Key {
+	keys := map[string]Key{}
+	for _, s := range c.All("key") {
+		k := Key{Name: s.Arg(0)}
+		if a, ok := s.Block.Get("algorithm"); ok {
+			k.Algorithm = a.Arg(0)
+		}
+		if sec, ok := s.Block.Get("secret"); ok {
+			k.Secret = sec.Arg(0)
+		}
+		keys[k.Name] = k
+	}
+	return keys
+}
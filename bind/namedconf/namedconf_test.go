@@ -0,0 +1,173 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namedconf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("named.conf", `
+// Main configuration.
+include "named.conf.options";
+/* A multi-line
+   comment. */
+zone "example.com" in {
+	type primary; # inline comment
+	file "/var/lib/bind/db.example.com";
+	allow-transfer { 192.0.2.1; !192.0.2.2; };
+};
+`)
+	write("named.conf.options", `options {
+	directory "/var/cache/bind";
+	recursive-clients 5000;
+	tcp-clients 200;
+};
+`)
+
+	c, err := ParseFile(filepath.Join(dir, "named.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Block{
+		{Name: "options", Block: Block{
+			{Name: "directory", Args: []string{"/var/cache/bind"}},
+			{Name: "recursive-clients", Args: []string{"5000"}},
+			{Name: "tcp-clients", Args: []string{"200"}},
+		}},
+		{Name: "zone", Args: []string{"example.com", "in"}, Block: Block{
+			{Name: "type", Args: []string{"primary"}},
+			{Name: "file", Args: []string{"/var/lib/bind/db.example.com"}},
+			{Name: "allow-transfer", Block: Block{
+				{Name: "192.0.2.1"},
+				{Name: "!192.0.2.2"},
+			}},
+		}},
+	}
+	if !reflect.DeepEqual(c.Block, want) {
+		t.Errorf("unexpected configuration:\n%#v\nwant:\n%#v", c.Block, want)
+	}
+
+	if got, want := c.Limits(), (Limits{RecursiveClients: 5000, TCPClients: 200}); got != want {
+		t.Errorf("want limits %+v, got %+v", want, got)
+	}
+
+	for _, name := range []string{"named.conf", "named.conf.options"} {
+		if _, ok := c.Files[filepath.Join(dir, name)]; !ok {
+			t.Errorf("expected %s in files read, got %v", name, c.Files)
+		}
+	}
+}
+
+func TestLimitsDefaults(t *testing.T) {
+	for conf, want := range map[string]Limits{
+		``:                                      {RecursiveClients: 1000, TCPClients: 150},
+		`options { recursive-clients 500; };`:   {RecursiveClients: 500, TCPClients: 150},
+		`options { tcp-clients 10; }; zone {};`: {RecursiveClients: 1000, TCPClients: 10},
+	} {
+		c, err := Parse(strings.NewReader(conf))
+		if err != nil {
+			t.Fatalf("%q: %s", conf, err)
+		}
+		if got := c.Limits(); got != want {
+			t.Errorf("%q: want limits %+v, got %+v", conf, want, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for conf, want := range map[string]string{
+		`options { tcp-clients 10; `:         "-:1: unexpected end of file",
+		"options {\n tcp-clients 10 };":      "-:2: missing ';' after \"tcp-clients\"",
+		`zone "example.com`:                  "-:1: unterminated string",
+		`/* comment`:                         "-:1: unterminated comment",
+		`};`:                                 "-:1: unexpected '}'",
+		`include "a" "b";`:                   "-:1: invalid include statement",
+		`include "/nonexistent/named.conf";`: "open /nonexistent/named.conf: no such file or directory",
+	} {
+		_, err := Parse(strings.NewReader(conf))
+		if err == nil || err.Error() != want {
+			t.Errorf("%q: want error %q, got %v", conf, want, err)
+		}
+	}
+}
+
+func TestZones(t *testing.T) {
+	c, err := Parse(strings.NewReader(`
+key "xfr" { algorithm hmac-sha256; secret "c2VjcmV0"; };
+primaries upstream port 5353 { 192.0.2.1; 2001:db8::1 key "xfr"; };
+masters legacy { upstream; 192.0.2.3 port 53; };
+statistics-channels {
+	inet 127.0.0.1 port 8053 allow { 127.0.0.1; };
+	inet * port 8080;
+	inet ::;
+};
+options { directory "/var/cache/bind"; key-directory "keys"; };
+zone "example.com." { type master; file "db.example.com"; dnssec-policy default; };
+zone "." { type hint; file "root.hints"; };
+view "internal" {
+	match-clients { !key "other"; key "xfr"; 10.0.0.0/8; };
+	inline-signing yes;
+	zone "example.org" in { type slave; primaries { legacy; 192.0.2.4; }; };
+	zone "example.net" { in-view "external"; };
+};
+view "external" {
+	masterfile-format text;
+	zone "version.bind" chaos { type primary; };
+};
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := c.Views(), []string{"internal", "external"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want views %v, got %v", want, got)
+	}
+
+	const keys = "/var/cache/bind/keys"
+	want := []Zone{
+		{View: "_default", Name: "example.com", Class: "IN", Type: "primary", File: "/var/cache/bind/db.example.com", Signed: true, KeyDirectory: keys},
+		{View: "_default", Name: ".", Class: "IN", Type: "hint", File: "/var/cache/bind/root.hints", KeyDirectory: keys},
+		{View: "internal", Name: "example.org", Class: "IN", Type: "secondary", Primaries: []Primary{
+			{Address: "192.0.2.1:5353"},
+			{Address: "[2001:db8::1]:5353", Key: "xfr"},
+			{Address: "192.0.2.3:53"},
+			{Address: "192.0.2.4:53"},
+		}, InlineSigning: true, KeyDirectory: keys, ViewKey: "xfr"},
+		{View: "internal", Name: "example.net", Class: "IN", Type: "in-view", InlineSigning: true, KeyDirectory: keys, ViewKey: "xfr"},
+		{View: "external", Name: "version.bind", Class: "CHAOS", Type: "primary", MasterfileFormat: "text", KeyDirectory: keys},
+	}
+	if got := c.Zones(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected zones:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	if got, want := c.Keys(), map[string]Key{"xfr": {Name: "xfr", Algorithm: "hmac-sha256", Secret: "c2VjcmV0"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want keys %v, got %v", want, got)
+	}
+
+	if got, want := c.StatisticsChannels(), []string{"127.0.0.1:8053", "127.0.0.1:8080", "[::1]:80"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want statistics channels %v, got %v", want, got)
+	}
+}
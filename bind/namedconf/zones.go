@@ -0,0 +1,260 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namedconf
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultView is the name of the view containing the zones configured
+// outside of a view statement.
+const DefaultView = "_default"
+
+// Zone types normalized from their legacy names.
+var zoneTypes = map[string]string{
+	"master": "primary",
+	"slave":  "secondary",
+}
+
+// Zone is a zone statement.
+type Zone struct {
+	View  string
+	Name  string
+	Class string
+	// Type is the zone type, using the current names "primary" and
+	// "secondary" for the legacy "master" and "slave".
+	Type string
+	// Primaries are the servers a secondary or stub zone transfers from.
+	Primaries []Primary
+	// File is the path of the zone file, relative paths are resolved
+	// against the directory option.
+	File string
+	// Signed is set if named maintains DNSSEC signatures of the zone.
+	Signed bool
+	// InlineSigning is set if the signed zone is kept separately, in the
+	// file with the suffix ".signed".
+	InlineSigning bool
+	// MasterfileFormat is the configured format of the zone files, empty if
+	// not configured.
+	MasterfileFormat string
+	// KeyDirectory is the directory of the DNSSEC keys of the zone.
+	KeyDirectory string
+	// ViewKey is the first TSIG key in the match-clients of the view of the
+	// zone, which requests select the view with. Empty if there is none.
+	ViewKey string
+}
+
+// Primary is a server a zone is transferred from.
+type Primary struct {
+	// Address is the host and port of the server.
+	Address string
+	Key     string
+}
+
+// Loaded reports whether named loads the zone into the zone table of its
+// view. Zones which only modify the resolution of a view, like forward and
+// hint zones, are not reported in the zone statistics.
+func (z Zone) Loaded() bool {
+	switch z.Type {
+	case "forward", "hint", "delegation-only", "in-view":
+		return false
+	}
+	return true
+}
+
+// Views returns the names of the configured views.
+func (c *Config) Views() []string {
+	var views []string
+	for _, v := range c.All("view") {
+		views = append(views, v.Arg(0))
+	}
+	return views
+}
+
+// Zones returns all zones of the configuration, in the order they are
+// configured.
+func (c *Config) Zones() []Zone {
+	var zones []Zone
+	for _, s := range c.Block {
+		switch s.Name {
+		case "zone":
+			zones = append(zones, c.zone(DefaultView, nil, s))
+		case "view":
+			for _, z := range s.Block.All("zone") {
+				zones = append(zones, c.zone(s.Arg(0), s.Block, z))
+			}
+		}
+	}
+	return zones
+}
+
+func (c *Config) zone(view string, viewBlock Block, s Statement) Zone {
+	z := Zone{
+		View:  view,
+		Name:  zoneName(s.Arg(0)),
+		Class: "IN",
+	}
+	if class := s.Arg(1); class != "" {
+		z.Class = strings.ToUpper(class)
+	}
+	if t, ok := s.Block.Get("type"); ok {
+		z.Type = t.Arg(0)
+		if n, ok := zoneTypes[z.Type]; ok {
+			z.Type = n
+		}
+	}
+	if _, ok := s.Block.Get("in-view"); ok {
+		z.Type = "in-view"
+	}
+	p, ok := s.Block.Get("primaries")
+	if !ok {
+		p, ok = s.Block.Get("masters")
+	}
+	if ok {
+		z.Primaries = c.primaries(p, map[string]bool{})
+	}
+
+	// Options of a zone are inherited from its view and the global options.
+	inherited := func(name string) (Statement, bool) {
+		for _, b := range []Block{s.Block, viewBlock, c.Options()} {
+			if o, ok := b.Get(name); ok {
+				return o, true
+			}
+		}
+		return Statement{}, false
+	}
+	if f, ok := s.Block.Get("file"); ok {
+		z.File = c.path(f.Arg(0))
+	}
+	z.KeyDirectory = c.path(".")
+	if d, ok := inherited("key-directory"); ok {
+		z.KeyDirectory = c.path(d.Arg(0))
+	}
+	if o, ok := inherited("masterfile-format"); ok {
+		z.MasterfileFormat = o.Arg(0)
+	}
+	if o, ok := inherited("inline-signing"); ok {
+		z.InlineSigning = isTrue(o.Arg(0))
+	}
+	if o, ok := inherited("dnssec-policy"); ok && o.Arg(0) != "none" {
+		z.Signed = true
+	}
+	if o, ok := inherited("auto-dnssec"); ok && o.Arg(0) == "maintain" {
+		z.Signed = true
+	}
+	if m, ok := viewBlock.Get("match-clients"); ok {
+		if k, ok := m.Block.Get("key"); ok {
+			z.ViewKey = k.Arg(0)
+		}
+	}
+	return z
+}
+
+// path resolves a path relative to the directory option.
+func (c *Config) path(p string) string {
+	d, ok := c.Options().Get("directory")
+	if !ok || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(d.Arg(0), p)
+}
+
+func isTrue(s string) bool {
+	switch s {
+	case "yes", "true", "1":
+		return true
+	}
+	return false
+}
+
+// zoneName returns a zone name in the format of the statistics channel.
+func zoneName(n string) string {
+	if n != "." {
+		n = strings.TrimSuffix(n, ".")
+	}
+	return n
+}
+
+// primaries resolves a primaries statement, following references to named
+// primaries lists. seen guards against reference loops.
+func (c *Config) primaries(s Statement, seen map[string]bool) []Primary {
+	port, key := "53", ""
+	parseOptions(s.Args, &port, &key)
+
+	var ps []Primary
+	for _, e := range s.Block {
+		if ip := net.ParseIP(e.Name); ip != nil {
+			p, k := port, key
+			parseOptions(e.Args, &p, &k)
+			ps = append(ps, Primary{Address: net.JoinHostPort(e.Name, p), Key: k})
+			continue
+		}
+		if seen[e.Name] {
+			continue
+		}
+		seen[e.Name] = true
+		for _, l := range append(c.All("primaries"), c.All("masters")...) {
+			if l.Arg(0) == e.Name {
+				ps = append(ps, c.primaries(l, seen)...)
+			}
+		}
+	}
+	return ps
+}
+
+// parseOptions reads the port and key options from the arguments of a
+// primaries list or element.
+func parseOptions(args []string, port, key *string) {
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "port":
+			*port = args[i+1]
+			i++
+		case "key":
+			*key = args[i+1]
+			i++
+		}
+	}
+}
+
+// StatisticsChannels returns the addresses of the configured statistics
+// channels as host and port. Wildcard addresses are replaced by the loopback
+// address.
+func (c *Config) StatisticsChannels() []string {
+	var addrs []string
+	for _, s := range c.All("statistics-channels") {
+		for _, e := range s.Block.All("inet") {
+			host, port := e.Arg(0), "80"
+			for i := 1; i+1 < len(e.Args); i++ {
+				if e.Args[i] == "port" {
+					port = e.Args[i+1]
+				}
+			}
+			switch host {
+			case "*":
+				host = "127.0.0.1"
+			case "::":
+				host = "::1"
+			}
+			if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+				continue
+			}
+			addrs = append(addrs, net.JoinHostPort(host, port))
+		}
+	}
+	return addrs
+}
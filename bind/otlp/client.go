@@ -0,0 +1,122 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Protocol is the transport of OTLP requests.
+type Protocol string
+
+// Protocols, named as in OTEL_EXPORTER_OTLP_PROTOCOL.
+const (
+	HTTP Protocol = "http/protobuf"
+	GRPC Protocol = "grpc"
+)
+
+// exportMethod is the path of the gRPC method exporting metrics.
+const exportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+// Client sends export requests to an OTLP endpoint.
+type Client struct {
+	endpoint  string
+	protocol  Protocol
+	client    *http.Client
+	userAgent string
+}
+
+// NewClient returns a client sending export requests with client. The
+// endpoint is the full URL for HTTP, e.g. http://localhost:4318/v1/metrics,
+// and the URL of the server for gRPC, e.g. http://localhost:4317. For gRPC,
+// client must support HTTP/2, also without TLS if the endpoint is http.
+func NewClient(endpoint string, protocol Protocol, client *http.Client, userAgent string) *Client {
+	return &Client{endpoint: endpoint, protocol: protocol, client: client, userAgent: userAgent}
+}
+
+// Export sends a serialized export request.
+func (c *Client) Export(ctx context.Context, req []byte) error {
+	if c.protocol == GRPC {
+		return c.exportGRPC(ctx, req)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(req))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	r.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	return fmt.Errorf("server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(body))
+}
+
+// exportGRPC sends an export request as a unary gRPC call, a single
+// length-prefixed message whose status is returned in the trailers.
+func (c *Client) exportGRPC(ctx context.Context, req []byte) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.endpoint, "/")+exportMethod, bytes.NewReader(grpcFrame(req)))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/grpc")
+	r.Header.Set("Te", "trailers")
+	r.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned HTTP status %s", resp.Status)
+	}
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+	// Responses without a message carry the status in the headers.
+	status, msg := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, msg = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	switch status {
+	case "0":
+		return nil
+	case "":
+		return errors.New("server returned no gRPC status")
+	default:
+		return fmt.Errorf("server returned gRPC status %s: %s", status, msg)
+	}
+}
+
+// grpcFrame prefixes an uncompressed message with its length.
+func grpcFrame(m []byte) []byte {
+	b := make([]byte, 5, 5+len(m))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(m)))
+	return append(b, m...)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/internal/wire"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestMarshalPointSum(t *testing.T) {
+	for _, tc := range []struct {
+		sum  float64
+		want bool
+	}{
+		{sum: 0.75, want: true},
+		{sum: 0, want: true},
+		// BIND doesn't report the sum of its query durations.
+		{sum: math.NaN(), want: false},
+	} {
+		p := Point{Time: time.Unix(1700000030, 0), Count: 7, Sum: tc.sum, Bounds: []float64{0.1}, BucketCounts: []uint64{3, 4}}
+		var (
+			found bool
+			sum   float64
+		)
+		err := wire.Fields(marshalPoint(Histogram, p), func(num protowire.Number, _ protowire.Type, v uint64, _ []byte) {
+			if num == histogramPointSum {
+				found, sum = true, math.Float64frombits(v)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != tc.want {
+			t.Errorf("sum %v: got sum field %t, want %t", tc.sum, found, tc.want)
+		} else if found && sum != tc.sum {
+			t.Errorf("sum %v: got %v", tc.sum, sum)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp exports metrics with the OpenTelemetry protocol (OTLP) over
+// HTTP or gRPC. Only the parts of the metrics data model needed to represent
+// Prometheus metrics are supported.
+package otlp
+
+import (
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Attribute is a string attribute of a resource or data point.
+type Attribute struct {
+	Key, Value string
+}
+
+// MetricType is the type of the data of a metric.
+type MetricType int
+
+// Metric types.
+const (
+	Gauge MetricType = iota
+	Sum
+	Histogram
+	Summary
+)
+
+// Quantile is a quantile of a summary data point.
+type Quantile struct {
+	Quantile, Value float64
+}
+
+// Point is a data point of a metric. Gauge and sum points have a value,
+// histogram and summary points a count and sum of observations.
+type Point struct {
+	Attributes []Attribute
+	// Start is the time from which cumulative points accumulate, zero if
+	// unknown.
+	Start time.Time
+	Time  time.Time
+
+	Value float64
+
+	Count uint64
+	Sum   float64
+	// Bounds are the upper bounds of the histogram buckets but the last,
+	// which is unbounded. BucketCounts holds the number of observations of
+	// each bucket, not cumulative.
+	Bounds       []float64
+	BucketCounts []uint64
+	Quantiles    []Quantile
+}
+
+// Metric is a metric with its data points. Sums and histograms are cumulative.
+type Metric struct {
+	Name        string
+	Description string
+	Unit        string
+	Type        MetricType
+	// Monotonic is set for sums which only increase, like counters.
+	Monotonic bool
+	Points    []Point
+}
+
+// Request is a request exporting metrics of a single resource and
+// instrumentation scope.
+type Request struct {
+	Resource     []Attribute
+	ScopeName    string
+	ScopeVersion string
+	Metrics      []Metric
+}
+
+// Field numbers of the protobuf messages of the metrics service.
+const (
+	exportRequestResourceMetrics protowire.Number = 1
+
+	resourceMetricsResource     protowire.Number = 1
+	resourceMetricsScopeMetrics protowire.Number = 2
+
+	resourceAttributes protowire.Number = 1
+
+	keyValueKey   protowire.Number = 1
+	keyValueValue protowire.Number = 2
+
+	anyValueString protowire.Number = 1
+
+	scopeMetricsScope   protowire.Number = 1
+	scopeMetricsMetrics protowire.Number = 2
+
+	scopeName    protowire.Number = 1
+	scopeVersion protowire.Number = 2
+
+	metricName        protowire.Number = 1
+	metricDescription protowire.Number = 2
+	metricUnit        protowire.Number = 3
+	metricGauge       protowire.Number = 5
+	metricSum         protowire.Number = 7
+	metricHistogram   protowire.Number = 9
+	metricSummary     protowire.Number = 11
+
+	// The data points of gauges, sums, histograms and summaries.
+	dataPoints             protowire.Number = 1
+	aggregationTemporality protowire.Number = 2
+	sumIsMonotonic         protowire.Number = 3
+
+	pointStart protowire.Number = 2
+	pointTime  protowire.Number = 3
+
+	numberPointDouble     protowire.Number = 4
+	numberPointInt        protowire.Number = 6
+	numberPointAttributes protowire.Number = 7
+
+	histogramPointCount        protowire.Number = 4
+	histogramPointSum          protowire.Number = 5
+	histogramPointBucketCounts protowire.Number = 6
+	histogramPointBounds       protowire.Number = 7
+	histogramPointAttributes   protowire.Number = 9
+
+	summaryPointCount      protowire.Number = 4
+	summaryPointSum        protowire.Number = 5
+	summaryPointQuantiles  protowire.Number = 6
+	summaryPointAttributes protowire.Number = 7
+
+	quantileQuantile protowire.Number = 1
+	quantileValue    protowire.Number = 2
+
+	temporalityCumulative = 2
+)
+
+// Marshal serializes a request as an ExportMetricsServiceRequest protobuf.
+func Marshal(r Request) []byte {
+	var res []byte
+	for _, a := range r.Resource {
+		res = appendMessage(res, resourceAttributes, marshalAttribute(a))
+	}
+
+	var scope []byte
+	scope = appendString(scope, scopeName, r.ScopeName)
+	scope = appendString(scope, scopeVersion, r.ScopeVersion)
+	var sm []byte
+	sm = appendMessage(sm, scopeMetricsScope, scope)
+	for _, m := range r.Metrics {
+		sm = appendMessage(sm, scopeMetricsMetrics, marshalMetric(m))
+	}
+
+	var rm []byte
+	rm = appendMessage(rm, resourceMetricsResource, res)
+	rm = appendMessage(rm, resourceMetricsScopeMetrics, sm)
+	return appendMessage(nil, exportRequestResourceMetrics, rm)
+}
+
+func marshalMetric(m Metric) []byte {
+	var data []byte
+	for _, p := range m.Points {
+		data = appendMessage(data, dataPoints, marshalPoint(m.Type, p))
+	}
+	field := metricGauge
+	switch m.Type {
+	case Sum:
+		field = metricSum
+		data = protowire.AppendTag(data, aggregationTemporality, protowire.VarintType)
+		data = protowire.AppendVarint(data, temporalityCumulative)
+		data = protowire.AppendTag(data, sumIsMonotonic, protowire.VarintType)
+		data = protowire.AppendVarint(data, protowire.EncodeBool(m.Monotonic))
+	case Histogram:
+		field = metricHistogram
+		data = protowire.AppendTag(data, aggregationTemporality, protowire.VarintType)
+		data = protowire.AppendVarint(data, temporalityCumulative)
+	case Summary:
+		field = metricSummary
+	}
+
+	var b []byte
+	b = appendString(b, metricName, m.Name)
+	b = appendString(b, metricDescription, m.Description)
+	b = appendString(b, metricUnit, m.Unit)
+	return appendMessage(b, field, data)
+}
+
+func marshalPoint(t MetricType, p Point) []byte {
+	var b []byte
+	if !p.Start.IsZero() {
+		b = appendFixed64(b, pointStart, uint64(p.Start.UnixNano()))
+	}
+	b = appendFixed64(b, pointTime, uint64(p.Time.UnixNano()))
+
+	attributes := numberPointAttributes
+	switch t {
+	case Gauge, Sum:
+		b = appendFixed64(b, numberPointDouble, math.Float64bits(p.Value))
+	case Histogram:
+		attributes = histogramPointAttributes
+		b = appendFixed64(b, histogramPointCount, p.Count)
+		// The sum is optional, left out where it isn't known, e.g. for
+		// BIND's query durations.
+		if !math.IsNaN(p.Sum) {
+			b = appendFixed64(b, histogramPointSum, math.Float64bits(p.Sum))
+		}
+		var counts, bounds []byte
+		for _, c := range p.BucketCounts {
+			counts = protowire.AppendFixed64(counts, c)
+		}
+		for _, f := range p.Bounds {
+			bounds = protowire.AppendFixed64(bounds, math.Float64bits(f))
+		}
+		b = appendMessage(b, histogramPointBucketCounts, counts)
+		b = appendMessage(b, histogramPointBounds, bounds)
+	case Summary:
+		attributes = summaryPointAttributes
+		b = appendFixed64(b, summaryPointCount, p.Count)
+		b = appendFixed64(b, summaryPointSum, math.Float64bits(p.Sum))
+		for _, q := range p.Quantiles {
+			var qb []byte
+			qb = appendFixed64(qb, quantileQuantile, math.Float64bits(q.Quantile))
+			qb = appendFixed64(qb, quantileValue, math.Float64bits(q.Value))
+			b = appendMessage(b, summaryPointQuantiles, qb)
+		}
+	}
+	for _, a := range p.Attributes {
+		b = appendMessage(b, attributes, marshalAttribute(a))
+	}
+	return b
+}
+
+func marshalAttribute(a Attribute) []byte {
+	var v []byte
+	v = protowire.AppendTag(v, anyValueString, protowire.BytesType)
+	v = protowire.AppendString(v, a.Value)
+	var b []byte
+	b = appendString(b, keyValueKey, a.Key)
+	return appendMessage(b, keyValueValue, v)
+}
+
+func appendMessage(b []byte, num protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendFixed64(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, v)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/otlp"
+	"github.com/prometheus-community/bind_exporter/internal/otlptest"
+)
+
+var (
+	start = time.Unix(1700000000, 0)
+	now   = time.Unix(1700000030, 500)
+
+	testRequest = otlp.Request{
+		Resource:     []otlp.Attribute{{Key: "service.name", Value: "bind"}, {Key: "service.version", Value: "9.18.24"}},
+		ScopeName:    "bind_exporter",
+		ScopeVersion: "0.9.0",
+		Metrics: []otlp.Metric{
+			{
+				Name:        "bind_incoming_queries",
+				Description: "Number of incoming DNS queries.",
+				Type:        otlp.Sum,
+				Monotonic:   true,
+				Points: []otlp.Point{
+					{Attributes: []otlp.Attribute{{Key: "type", Value: "A"}}, Start: start, Time: now, Value: 128417},
+					{Attributes: []otlp.Attribute{{Key: "type", Value: "AAAA"}}, Start: start, Time: now, Value: 37605},
+				},
+			},
+			{
+				Name:   "bind_up",
+				Type:   otlp.Gauge,
+				Points: []otlp.Point{{Time: now, Value: 1}},
+			},
+			{
+				Name: "bind_resolver_query_duration_seconds",
+				Type: otlp.Histogram,
+				Points: []otlp.Point{{
+					Attributes:   []otlp.Attribute{{Key: "view", Value: "_default"}},
+					Start:        start,
+					Time:         now,
+					Count:        7,
+					Sum:          0.75,
+					Bounds:       []float64{0.01, 0.1},
+					BucketCounts: []uint64{2, 3, 2},
+				}},
+			},
+			{
+				Name: "go_gc_duration_seconds",
+				Type: otlp.Summary,
+				Points: []otlp.Point{{
+					Time:      now,
+					Count:     3,
+					Sum:       0.003,
+					Quantiles: []otlp.Quantile{{Quantile: 0, Value: 0.0005}, {Quantile: 1, Value: 0.0015}},
+				}},
+			},
+		},
+	}
+)
+
+func equalRequests(t *testing.T, got, want otlp.Request) {
+	t.Helper()
+	// Compare times separately as they lose their location when decoded.
+	for i := range got.Metrics {
+		if i >= len(want.Metrics) || len(got.Metrics[i].Points) != len(want.Metrics[i].Points) {
+			break
+		}
+		for j := range got.Metrics[i].Points {
+			g, w := &got.Metrics[i].Points[j], want.Metrics[i].Points[j]
+			if !g.Start.Equal(w.Start) || !g.Time.Equal(w.Time) {
+				t.Errorf("%s: got times %v, %v, want %v, %v", want.Metrics[i].Name, g.Start, g.Time, w.Start, w.Time)
+			}
+			g.Start, g.Time = w.Start, w.Time
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	got, err := otlptest.Unmarshal(otlp.Marshal(testRequest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalRequests(t, got, testRequest)
+
+	if _, err := otlptest.Unmarshal([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
+
+func TestClient(t *testing.T) {
+	var received []otlp.Request
+	receiver := otlptest.Receiver(func(r otlp.Request) { received = append(received, r) })
+
+	httpServer := httptest.NewServer(receiver)
+	defer httpServer.Close()
+
+	tlsServer := httptest.NewUnstartedServer(receiver)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	h2cServer := httptest.NewUnstartedServer(receiver)
+	h2cServer.Config.Protocols = new(http.Protocols)
+	h2cServer.Config.Protocols.SetUnencryptedHTTP2(true)
+	h2cServer.Start()
+	defer h2cServer.Close()
+	h2c := &http.Transport{Protocols: new(http.Protocols)}
+	h2c.Protocols.SetUnencryptedHTTP2(true)
+
+	for name, c := range map[string]*otlp.Client{
+		"http":      otlp.NewClient(httpServer.URL+"/v1/metrics", otlp.HTTP, httpServer.Client(), "test"),
+		"grpc":      otlp.NewClient(tlsServer.URL, otlp.GRPC, tlsServer.Client(), "test"),
+		"grpc, h2c": otlp.NewClient(h2cServer.URL, otlp.GRPC, &http.Client{Transport: h2c}, "test"),
+	} {
+		received = nil
+		if err := c.Export(context.Background(), otlp.Marshal(testRequest)); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(received) != 1 {
+			t.Errorf("%s: received %d requests, want 1", name, len(received))
+			continue
+		}
+		equalRequests(t, received[0], testRequest)
+
+		if err := c.Export(context.Background(), []byte{0x0a, 0x05, 0x01}); err == nil {
+			t.Errorf("%s: expected error for invalid request", name)
+		}
+	}
+}
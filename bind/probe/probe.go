@@ -0,0 +1,161 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package probe sends DNS queries to check that named answers as expected.
+package probe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"go.yaml.in/yaml/v2"
+)
+
+// DefaultTimeout is the timeout of a probe not configuring one.
+const DefaultTimeout = 5 * time.Second
+
+// Config is the probe configuration file, e.g.
+//
+//	probes:
+//	  - name: root
+//	    query: .
+//	    type: NS
+//	    dnssec: true
+//	    expect_ad: true
+type Config struct {
+	Probes []Probe `yaml:"probes"`
+}
+
+// Probe is a query and its expected response.
+type Probe struct {
+	Name  string `yaml:"name"`
+	Query string `yaml:"query"`
+	// Type is the query type, A if not set.
+	Type string `yaml:"type"`
+	// Transport is either "udp", the default, or "tcp".
+	Transport string `yaml:"transport"`
+	// DNSSEC sets the DO bit.
+	DNSSEC bool `yaml:"dnssec"`
+	// ExpectRcode is the expected response code, NOERROR if not set.
+	ExpectRcode string `yaml:"expect_rcode"`
+	// ExpectAD, if set, requires the AD flag to be set or not.
+	ExpectAD *bool         `yaml:"expect_ad"`
+	Timeout  time.Duration `yaml:"timeout"`
+
+	qtype uint16
+	rcode int
+}
+
+// LoadConfig reads and validates the configuration file at path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", path, err)
+	}
+
+	names := map[string]bool{}
+	for i := range c.Probes {
+		p := &c.Probes[i]
+		if err := p.init(); err != nil {
+			return nil, fmt.Errorf("invalid probe %q: %s", p.Name, err)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("duplicate probe %q", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return c, nil
+}
+
+func (p *Probe) init() error {
+	if p.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if p.Query == "" {
+		return fmt.Errorf("missing query")
+	}
+	if p.Type == "" {
+		p.Type = "A"
+	}
+	var ok bool
+	if p.qtype, ok = dns.StringToType[strings.ToUpper(p.Type)]; !ok {
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+	switch p.Transport {
+	case "":
+		p.Transport = "udp"
+	case "udp", "tcp":
+	default:
+		return fmt.Errorf("unknown transport %q", p.Transport)
+	}
+	if p.ExpectRcode == "" {
+		p.ExpectRcode = "NOERROR"
+	}
+	if p.rcode, ok = dns.StringToRcode[strings.ToUpper(p.ExpectRcode)]; !ok {
+		return fmt.Errorf("unknown rcode %q", p.ExpectRcode)
+	}
+	if p.Timeout == 0 {
+		p.Timeout = DefaultTimeout
+	}
+	return nil
+}
+
+// Result is the outcome of running a probe.
+type Result struct {
+	Success  bool
+	Duration time.Duration
+	// Validated is set if the response had the AD flag set.
+	Validated bool
+	Err       error
+}
+
+// Run sends the query of the probe to target and checks the response.
+func (p Probe) Run(ctx context.Context, target string) Result {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(p.Query), p.qtype)
+	if p.DNSSEC {
+		m.SetEdns0(dns.DefaultMsgSize, true)
+		m.AuthenticatedData = true
+	}
+
+	c := &dns.Client{Net: p.Transport, Timeout: p.Timeout}
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	r, _, err := c.ExchangeContext(ctx, m, target)
+	res := Result{Duration: time.Since(start)}
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	res.Validated = r.AuthenticatedData
+	switch {
+	case r.Rcode != p.rcode:
+		res.Err = fmt.Errorf("unexpected rcode %s", dns.RcodeToString[r.Rcode])
+	case p.ExpectAD != nil && *p.ExpectAD != r.AuthenticatedData:
+		res.Err = fmt.Errorf("unexpected AD flag %t", r.AuthenticatedData)
+	default:
+		res.Success = true
+	}
+	return res
+}
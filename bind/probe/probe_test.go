@@ -0,0 +1,116 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// startServer starts a DNS server on UDP and TCP answering with rcode, setting
+// the AD flag for queries with the DO bit.
+func startServer(t *testing.T, rcode int) string {
+	h := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, rcode)
+		if o := r.IsEdns0(); o != nil && o.Do() {
+			m.AuthenticatedData = true
+		}
+		w.WriteMsg(m)
+	})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*dns.Server{{PacketConn: pc, Handler: h}, {Listener: l, Handler: h}} {
+		go s.ActivateAndServe()
+		t.Cleanup(func() { s.Shutdown() })
+	}
+	return pc.LocalAddr().String()
+}
+
+func TestRun(t *testing.T) {
+	ok := startServer(t, dns.RcodeSuccess)
+	servfail := startServer(t, dns.RcodeServerFailure)
+	yes, no := true, false
+
+	for _, tc := range []struct {
+		name      string
+		probe     Probe
+		target    string
+		success   bool
+		validated bool
+	}{
+		{"udp", Probe{Query: "example.com"}, ok, true, false},
+		{"tcp", Probe{Query: "example.com", Transport: "tcp"}, ok, true, false},
+		{"servfail", Probe{Query: "example.com"}, servfail, false, false},
+		{"expected servfail", Probe{Query: "example.com", ExpectRcode: "SERVFAIL"}, servfail, true, false},
+		{"validated", Probe{Query: "example.com", DNSSEC: true, ExpectAD: &yes}, ok, true, true},
+		{"not validated", Probe{Query: "example.com", ExpectAD: &yes}, ok, false, false},
+		{"unexpected validation", Probe{Query: "example.com", DNSSEC: true, ExpectAD: &no}, ok, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.probe.Name = tc.name
+			if err := tc.probe.init(); err != nil {
+				t.Fatal(err)
+			}
+			r := tc.probe.Run(context.Background(), tc.target)
+			if r.Success != tc.success || r.Validated != tc.validated {
+				t.Errorf("want success %t and validated %t, got %+v", tc.success, tc.validated, r)
+			}
+			if r.Duration <= 0 {
+				t.Errorf("want positive duration, got %s", r.Duration)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	for content, want := range map[string]string{
+		"probes:\n- name: a\n  query: example.com\n  type: AAAA\n  transport: tcp\n  timeout: 1s\n": "",
+		"probes:\n- name: a\n  query: example.com\n  type: BOGUS\n":                                 `invalid probe "a": unknown type "BOGUS"`,
+		"probes:\n- name: a\n  query: example.com\n  transport: quic\n":                             `invalid probe "a": unknown transport "quic"`,
+		"probes:\n- name: a\n  query: example.com\n  expect_rcode: WHATEVER\n":                      `invalid probe "a": unknown rcode "WHATEVER"`,
+		"probes:\n- name: a\n  query: a.\n- name: a\n  query: b.\n":                                 `duplicate probe "a"`,
+		"probes:\n- query: a.\n": `invalid probe "": missing name`,
+	} {
+		path := filepath.Join(dir, "probes.yml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		c, err := LoadConfig(path)
+		if want == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %s", content, err)
+			} else if p := c.Probes[0]; p.qtype != dns.TypeAAAA || p.rcode != dns.RcodeSuccess {
+				t.Errorf("%q: unexpected probe %+v", content, p)
+			}
+			continue
+		}
+		if err == nil || err.Error() != want {
+			t.Errorf("%q: want error %q, got %v", content, want, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package querylog reads the query log of named.
+package querylog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Transports of a query.
+const (
+	UDP = "udp"
+	TCP = "tcp"
+)
+
+// Query is a single query log entry, e.g.
+//
+//	client @0x7f1c 192.0.2.1#53124 (example.com): view internal: query: example.com IN A +E(0)K (192.0.2.53)
+//
+// The timestamp, category and severity prefixes written depending on the
+// logging configuration are ignored.
+type Query struct {
+	Client string
+	// View is empty if named only has the default view, in which case it is
+	// not logged.
+	View  string
+	Name  string
+	Class string
+	Type  string
+	// Flags are the query flags, e.g. "+E(0)K".
+	Flags     string
+	Transport string
+	// Recursive is set if recursion was desired.
+	Recursive bool
+	// Server is the address the query was received on.
+	Server string
+}
+
+var queryRE = regexp.MustCompile(
+	`client (?:@0x[0-9a-fA-F]+ )?(\S+)#\d+(?: \([^)]*\))?: (?:view (\S+): )?query: (\S+) (\S+) (\S+) ([-+]\S*) \(([^)]*)\)`,
+)
+
+// ParseLine parses a query log line. It returns false if the line is not a
+// query.
+func ParseLine(line string) (Query, bool) {
+	m := queryRE.FindStringSubmatch(line)
+	if m == nil {
+		return Query{}, false
+	}
+	q := Query{
+		Client:    m[1],
+		View:      m[2],
+		Name:      strings.ToLower(m[3]),
+		Class:     m[4],
+		Type:      m[5],
+		Flags:     m[6],
+		Transport: UDP,
+		Recursive: m[6][0] == '+',
+		Server:    m[7],
+	}
+	// Flags other than the recursion flag are single letters, EDNS is
+	// followed by its version.
+	if strings.ContainsRune(stripEDNSVersion(m[6][1:]), 'T') {
+		q.Transport = TCP
+	}
+	return q, true
+}
+
+func stripEDNSVersion(flags string) string {
+	if i := strings.IndexByte(flags, '('); i >= 0 {
+		if j := strings.IndexByte(flags[i:], ')'); j >= 0 {
+			return flags[:i] + flags[i+j+1:]
+		}
+	}
+	return flags
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querylog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	f, err := os.Open("../../fixtures/querylog/query.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var got []Query
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if q, ok := ParseLine(s.Text()); ok {
+			got = append(got, q)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Query{
+		{Client: "192.0.2.1", View: "internal", Name: "example.com", Class: "IN", Type: "A", Flags: "+E(0)K", Transport: UDP, Recursive: true, Server: "192.0.2.53"},
+		{Client: "192.0.2.1", View: "internal", Name: "example.com", Class: "IN", Type: "AAAA", Flags: "+E(0)K", Transport: UDP, Recursive: true, Server: "192.0.2.53"},
+		{Client: "192.0.2.1", View: "internal", Name: "example.org", Class: "IN", Type: "MX", Flags: "+ET(0)DC", Transport: TCP, Recursive: true, Server: "192.0.2.53"},
+		{Client: "2001:db8::2", View: "external", Name: "example.com", Class: "IN", Type: "A", Flags: "-E(0)", Transport: UDP, Server: "2001:db8::53"},
+		{Client: "198.51.100.7", Name: "version.bind", Class: "CH", Type: "TXT", Flags: "-T", Transport: TCP, Server: "192.0.2.53"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected queries:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "query.log")
+	write := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	poll := func(tl *Tailer, want ...string) {
+		t.Helper()
+		var got []string
+		if err := tl.Poll(func(l string) { got = append(got, l) }); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("want lines %q, got %q", want, got)
+		}
+	}
+
+	tl := NewTailer(path)
+	defer tl.Close()
+
+	// Existing content is skipped.
+	write("old\n")
+	poll(tl)
+	write("one\ntw")
+	poll(tl, "one")
+	write("o\n")
+	poll(tl, "two")
+
+	// Renamed and recreated.
+	write("three\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	poll(tl, "three")
+	write("four\n")
+	poll(tl, "four")
+
+	// Truncated in place.
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	write("5\n")
+	poll(tl, "5")
+
+	// Lines too long are discarded as a whole, also when their end is read
+	// in a later poll.
+	var tooLong int
+	tl.TooLong = func() { tooLong++ }
+	long := strings.Repeat("x", maxLineLength)
+	write("a" + long + "b\nsix\n")
+	poll(tl, "six")
+	write(long)
+	poll(tl)
+	write("c\nseven\n")
+	poll(tl, "seven")
+	write(long + "\n")
+	poll(tl, long)
+	if tooLong != 2 {
+		t.Errorf("got %d lines too long, want 2", tooLong)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	k := NewTopK(3)
+	for _, key := range []string{"a", "b", "a", "c", "a", "b", "d", "a"} {
+		k.Add(key)
+	}
+	want := []Item{
+		{Key: "a", Count: 4},
+		{Key: "b", Count: 2},
+	}
+	if got := k.Top(2); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+	// "d" replaced "c", inheriting its count as error.
+	if got := k.Top(3)[2]; got != (Item{Key: "d", Count: 2, Error: 1}) {
+		t.Errorf("unexpected replacement %+v", got)
+	}
+
+	k.Reset()
+	if got := k.Top(3); len(got) != 0 {
+		t.Errorf("want no items after reset, got %+v", got)
+	}
+}
+
+func TestTopKReplacement(t *testing.T) {
+	// The least frequent element, the one with the smallest key for equal
+	// counts, is replaced.
+	k := NewTopK(3)
+	for _, key := range []string{"c", "c", "b", "b", "a", "a", "a", "d", "e", "e"} {
+		k.Add(key)
+	}
+	want := []Item{
+		{Key: "e", Count: 4, Error: 2},
+		{Key: "a", Count: 3},
+		{Key: "d", Count: 3, Error: 2},
+	}
+	if got := k.Top(3); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	// Frequent elements are always found in a long stream.
+	k = NewTopK(10)
+	for i := range 10000 {
+		key := fmt.Sprint(i)
+		if i%4 == 0 {
+			key = "frequent"
+		}
+		k.Add(key)
+	}
+	if got := k.Top(1)[0]; got.Key != "frequent" || got.Count-got.Error > 2500 || got.Count < 2500 {
+		t.Errorf("unexpected top element %+v", got)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querylog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+)
+
+// maxLineLength bounds the memory used for a line without a newline.
+const maxLineLength = 64 * 1024
+
+// Tailer follows a growing log file. It detects files rotated by renaming
+// and by truncation, the latter once the file is shorter than the position
+// read.
+type Tailer struct {
+	// TooLong, if set, is called for each line discarded for exceeding
+	// maxLineLength.
+	TooLong func()
+
+	path string
+
+	f       *os.File
+	r       *bufio.Reader
+	offset  int64
+	partial []byte
+	// overflow is set while the rest of a line too long is discarded.
+	overflow bool
+	// started is set once the file has been opened. The first file is read
+	// from its end, files appearing after a rotation from their start.
+	started bool
+}
+
+// NewTailer returns a Tailer for the file at path.
+func NewTailer(path string) *Tailer {
+	return &Tailer{path: path}
+}
+
+// Poll passes all lines written since the last call to fn. Lines not yet
+// terminated by a newline are kept until they are complete.
+func (t *Tailer) Poll(fn func(string)) error {
+	for {
+		if t.f == nil {
+			if err := t.open(); err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					// The file may be recreated after a rotation.
+					return nil
+				}
+				return err
+			}
+		}
+
+		if err := t.read(fn); err != nil {
+			return err
+		}
+
+		rotated, err := t.rotated()
+		if err != nil || !rotated {
+			return err
+		}
+	}
+}
+
+// Close closes the file currently followed.
+func (t *Tailer) Close() error {
+	if t.f == nil {
+		return nil
+	}
+	err := t.f.Close()
+	t.f, t.r, t.offset, t.partial, t.overflow = nil, nil, 0, nil, false
+	return err
+}
+
+func (t *Tailer) open() error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	if !t.started {
+		if t.offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return err
+		}
+		t.started = true
+	}
+	t.f = f
+	t.r = bufio.NewReader(f)
+	return nil
+}
+
+func (t *Tailer) read(fn func(string)) error {
+	for {
+		b, err := t.r.ReadSlice('\n')
+		t.offset += int64(len(b))
+		if err == bufio.ErrBufferFull || err == io.EOF {
+			switch {
+			case t.overflow:
+			case len(t.partial)+len(b) <= maxLineLength:
+				t.partial = append(t.partial, b...)
+			default:
+				t.overflow, t.partial = true, t.partial[:0]
+			}
+			if err == io.EOF {
+				return nil
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		line := b[:len(b)-1]
+		if t.overflow || len(t.partial)+len(line) > maxLineLength {
+			// Only the end of the line was read, discard it as a whole.
+			t.overflow, t.partial = false, t.partial[:0]
+			if t.TooLong != nil {
+				t.TooLong()
+			}
+			continue
+		}
+		if len(t.partial) > 0 {
+			line = append(t.partial, line...)
+			t.partial = t.partial[:0]
+		}
+		fn(string(line))
+	}
+}
+
+// rotated reports whether the file at path was replaced or truncated. A
+// replaced file is closed to open the new one, a truncated file is read again
+// from its start.
+func (t *Tailer) rotated() (bool, error) {
+	fi, err := os.Stat(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	cur, err := t.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if !os.SameFile(fi, cur) {
+		return true, t.Close()
+	}
+	if cur.Size() >= t.offset {
+		return false, nil
+	}
+	if _, err := t.f.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	t.r.Reset(t.f)
+	t.offset, t.partial, t.overflow = 0, nil, false
+	return true, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querylog
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Item is an element counted by TopK.
+type Item struct {
+	Key string
+	// Count is an upper bound of the number of occurrences, exceeding the
+	// actual number by at most Error.
+	Count uint64
+	Error uint64
+}
+
+// TopK estimates the most frequent elements of a stream in constant memory
+// using the space-saving algorithm. Elements occurring more often than
+// 1/capacity of the stream are guaranteed to be counted. The elements are
+// kept in a min-heap by count, so that counting takes O(log capacity).
+type TopK struct {
+	capacity int
+	items    map[string]*entry
+	heap     entries
+}
+
+// entry is an Item with its position in the heap.
+type entry struct {
+	Item
+	index int
+}
+
+// entries is a min-heap of entries by count, and key for equal counts.
+type entries []*entry
+
+func (h entries) Len() int { return len(h) }
+
+func (h entries) Less(i, j int) bool {
+	if h[i].Count != h[j].Count {
+		return h[i].Count < h[j].Count
+	}
+	return h[i].Key < h[j].Key
+}
+
+func (h entries) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *entries) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entries) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// NewTopK returns a TopK tracking at most capacity elements.
+func NewTopK(capacity int) *TopK {
+	return &TopK{capacity: capacity, items: make(map[string]*entry, capacity), heap: make(entries, 0, capacity)}
+}
+
+// Add counts an occurrence of key.
+func (t *TopK) Add(key string) {
+	if e, ok := t.items[key]; ok {
+		e.Count++
+		heap.Fix(&t.heap, e.index)
+		return
+	}
+	if len(t.items) < t.capacity {
+		e := &entry{Item: Item{Key: key, Count: 1}}
+		t.items[key] = e
+		heap.Push(&t.heap, e)
+		return
+	}
+
+	// Replace the least frequent element, which the new one may have
+	// occurred as often as.
+	e := t.heap[0]
+	delete(t.items, e.Key)
+	e.Item = Item{Key: key, Count: e.Count + 1, Error: e.Count}
+	t.items[key] = e
+	heap.Fix(&t.heap, 0)
+}
+
+// Top returns the n most frequent elements, most frequent first.
+func (t *TopK) Top(n int) []Item {
+	items := make([]Item, 0, len(t.items))
+	for _, e := range t.items {
+		items = append(items, e.Item)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].Count != items[b].Count {
+			return items[a].Count > items[b].Count
+		}
+		return items[a].Key < items[b].Key
+	})
+	if len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+// Reset removes all elements.
+func (t *TopK) Reset() {
+	clear(t.items)
+	clear(t.heap)
+	t.heap = t.heap[:0]
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const bufferExt = ".snappy"
+
+// Buffer holds the compressed write requests which couldn't be sent yet,
+// oldest first, up to a maximum number of requests. If the buffer has a
+// directory, requests are also written to it so they survive restarts.
+type Buffer struct {
+	mu       sync.Mutex
+	dir      string
+	max      int
+	seq      uint64
+	requests []request
+}
+
+type request struct {
+	name string
+	b    []byte
+}
+
+// NewBuffer returns a buffer of up to max requests. If dir isn't empty, the
+// requests left in it are loaded.
+func NewBuffer(dir string, max int) (*Buffer, error) {
+	b := &Buffer{dir: dir, max: max}
+	if dir == "" {
+		return b, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "*"+bufferExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var seq uint64
+		if _, err := fmt.Sscanf(filepath.Base(name), "%d"+bufferExt, &seq); err == nil && seq > b.seq {
+			b.seq = seq
+		}
+		b.requests = append(b.requests, request{name: name, b: data})
+	}
+	// Drop the oldest requests if the maximum was lowered.
+	if _, err := b.trim(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Push appends a request, dropping the oldest ones if the buffer is full. It
+// returns the number of requests dropped.
+func (b *Buffer) Push(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := request{b: data}
+	if b.dir != "" {
+		b.seq++
+		r.name = filepath.Join(b.dir, fmt.Sprintf("%020d%s", b.seq, bufferExt))
+		if err := writeFile(r.name, data); err != nil {
+			return 0, err
+		}
+	}
+	b.requests = append(b.requests, r)
+	return b.trim()
+}
+
+// Peek returns the oldest request, or nil if the buffer is empty.
+func (b *Buffer) Peek() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.requests) == 0 {
+		return nil
+	}
+	return b.requests[0].b
+}
+
+// Pop removes the oldest request.
+func (b *Buffer) Pop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.requests) == 0 {
+		return nil
+	}
+	r := b.requests[0]
+	b.requests = b.requests[1:]
+	return remove(r.name)
+}
+
+// Len returns the number of requests in the buffer.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.requests)
+}
+
+func (b *Buffer) trim() (int, error) {
+	n := len(b.requests) - b.max
+	if n <= 0 {
+		return 0, nil
+	}
+	for _, r := range b.requests[:n] {
+		if err := remove(r.name); err != nil {
+			return 0, err
+		}
+	}
+	b.requests = b.requests[n:]
+	return n, nil
+}
+
+// writeFile writes a file atomically, so that a request is never loaded
+// partially after a crash.
+func writeFile(name string, data []byte) error {
+	tmp := strings.TrimSuffix(name, bufferExt) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+func remove(name string) error {
+	if name == "" {
+		return nil
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
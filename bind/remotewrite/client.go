@@ -0,0 +1,81 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+// RecoverableError is returned by Store for requests which may succeed when
+// retried, i.e. on network errors, 5xx and 429 responses.
+type RecoverableError struct {
+	error
+}
+
+func (e RecoverableError) Unwrap() error {
+	return e.error
+}
+
+// Client sends write requests to a remote-write endpoint.
+type Client struct {
+	url       string
+	client    *http.Client
+	userAgent string
+}
+
+// NewClient returns a client sending write requests to url with client.
+func NewClient(url string, client *http.Client, userAgent string) *Client {
+	return &Client{url: url, client: client, userAgent: userAgent}
+}
+
+// Encode compresses a serialized WriteRequest for Store.
+func Encode(req []byte) []byte {
+	return snappy.Encode(nil, req)
+}
+
+// Store sends a compressed write request.
+func (c *Client) Store(ctx context.Context, req []byte) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(req))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Encoding", "snappy")
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	r.Header.Set("User-Agent", c.userAgent)
+	r.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return RecoverableError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		// The request is stored, the body is only drained to reuse the
+		// connection.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	err = fmt.Errorf("server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(body))
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return RecoverableError{err}
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remotewrite sends metrics with the Prometheus remote-write protocol
+// 1.0: snappy-compressed WriteRequest protobuf messages.
+package remotewrite
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Label is a label of a time series.
+type Label struct {
+	Name, Value string
+}
+
+// Sample is a value of a time series at a timestamp in milliseconds.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// TimeSeries is a time series identified by its labels, including the
+// metric name as __name__.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Field numbers of the WriteRequest, TimeSeries, Label and Sample protobuf
+// messages.
+const (
+	writeRequestTimeSeries protowire.Number = 1
+
+	timeSeriesLabels  protowire.Number = 1
+	timeSeriesSamples protowire.Number = 2
+
+	labelName  protowire.Number = 1
+	labelValue protowire.Number = 2
+
+	sampleValue     protowire.Number = 1
+	sampleTimestamp protowire.Number = 2
+)
+
+// Marshal serializes time series as a WriteRequest protobuf.
+func Marshal(series []TimeSeries) []byte {
+	var b []byte
+	for _, s := range series {
+		var ts []byte
+		for _, l := range s.Labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, labelName, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.Name)
+			lb = protowire.AppendTag(lb, labelValue, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.Value)
+			ts = protowire.AppendTag(ts, timeSeriesLabels, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, lb)
+		}
+		for _, v := range s.Samples {
+			var sb []byte
+			sb = protowire.AppendTag(sb, sampleValue, protowire.Fixed64Type)
+			sb = protowire.AppendFixed64(sb, math.Float64bits(v.Value))
+			sb = protowire.AppendTag(sb, sampleTimestamp, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(v.Timestamp))
+			ts = protowire.AppendTag(ts, timeSeriesSamples, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, sb)
+		}
+		b = protowire.AppendTag(b, writeRequestTimeSeries, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return b
+}
+
+// FromMetricFamilies converts gathered metrics to time series with samples
+// at t, unless a metric has its own timestamp. Histograms and summaries are
+// split into their _bucket or quantile, _sum and _count series as in the
+// text exposition format. The external labels are added to all series which
+// don't have a label of the same name.
+func FromMetricFamilies(mfs []*dto.MetricFamily, t time.Time, external []Label) []TimeSeries {
+	var series []TimeSeries
+	for _, mf := range mfs {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			ts := t.UnixMilli()
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			add := func(suffix string, v float64, extra ...Label) {
+				labels := []Label{{Name: "__name__", Value: name + suffix}}
+				for _, l := range m.GetLabel() {
+					labels = append(labels, Label{Name: l.GetName(), Value: l.GetValue()})
+				}
+				labels = append(labels, extra...)
+				series = append(series, TimeSeries{
+					Labels:  withExternal(labels, external),
+					Samples: []Sample{{Value: v, Timestamp: ts}},
+				})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add("", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add("", m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add("", m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add("", q.GetValue(), Label{Name: "quantile", Value: formatFloat(q.GetQuantile())})
+				}
+				add("_sum", s.GetSampleSum())
+				add("_count", float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				inf := false
+				for _, b := range h.GetBucket() {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						inf = true
+					}
+					add("_bucket", float64(b.GetCumulativeCount()), Label{Name: "le", Value: formatFloat(b.GetUpperBound())})
+				}
+				if !inf {
+					add("_bucket", float64(h.GetSampleCount()), Label{Name: "le", Value: "+Inf"})
+				}
+				add("_sum", h.GetSampleSum())
+				add("_count", float64(h.GetSampleCount()))
+			}
+		}
+	}
+	return series
+}
+
+// withExternal adds the external labels missing from labels and sorts them
+// by name, as required by the protocol.
+func withExternal(labels, external []Label) []Label {
+	for _, e := range external {
+		found := false
+		for _, l := range labels {
+			if l.Name == e.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			labels = append(labels, e)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/remotewrite"
+	"github.com/prometheus-community/bind_exporter/internal/remotewritetest"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMarshal(t *testing.T) {
+	series := []remotewrite.TimeSeries{
+		{
+			Labels:  []remotewrite.Label{{Name: "__name__", Value: "bind_up"}, {Name: "instance", Value: "resolver1"}},
+			Samples: []remotewrite.Sample{{Value: 1, Timestamp: 1700000000000}},
+		},
+		{
+			Labels:  []remotewrite.Label{{Name: "__name__", Value: "bind_resolver_cache_rrsets"}, {Name: "type", Value: "A"}, {Name: "view", Value: "_default"}},
+			Samples: []remotewrite.Sample{{Value: 5120.5, Timestamp: 1700000000000}, {Value: math.Inf(1), Timestamp: 1700000030000}},
+		},
+	}
+	got, err := remotewritetest.Unmarshal(remotewrite.Marshal(series))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, series) {
+		t.Errorf("got %v, want %v", got, series)
+	}
+	if _, err := remotewritetest.Unmarshal([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
+
+func TestFromMetricFamilies(t *testing.T) {
+	mfs := []*dto.MetricFamily{
+		{
+			Name: proto.String("bind_incoming_queries_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{
+				Label:   []*dto.LabelPair{{Name: proto.String("type"), Value: proto.String("A")}},
+				Counter: &dto.Counter{Value: proto.Float64(42)},
+			}},
+		},
+		{
+			Name: proto.String("bind_resolver_query_duration_seconds"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{{Name: proto.String("instance"), Value: proto.String("internal")}},
+				Histogram: &dto.Histogram{
+					SampleCount: proto.Uint64(3),
+					SampleSum:   proto.Float64(0.25),
+					Bucket:      []*dto.Bucket{{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(2)}},
+				},
+			}},
+		},
+	}
+	ts := time.UnixMilli(1700000000000)
+	got := remotewrite.FromMetricFamilies(mfs, ts, []remotewrite.Label{{Name: "instance", Value: "resolver1"}})
+
+	sample := []remotewrite.Sample{{Timestamp: 1700000000000}}
+	want := []remotewrite.TimeSeries{
+		{Labels: []remotewrite.Label{{Name: "__name__", Value: "bind_incoming_queries_total"}, {Name: "instance", Value: "resolver1"}, {Name: "type", Value: "A"}}},
+		{Labels: []remotewrite.Label{{Name: "__name__", Value: "bind_resolver_query_duration_seconds_bucket"}, {Name: "instance", Value: "internal"}, {Name: "le", Value: "0.1"}}},
+		{Labels: []remotewrite.Label{{Name: "__name__", Value: "bind_resolver_query_duration_seconds_bucket"}, {Name: "instance", Value: "internal"}, {Name: "le", Value: "+Inf"}}},
+		{Labels: []remotewrite.Label{{Name: "__name__", Value: "bind_resolver_query_duration_seconds_sum"}, {Name: "instance", Value: "internal"}}},
+		{Labels: []remotewrite.Label{{Name: "__name__", Value: "bind_resolver_query_duration_seconds_count"}, {Name: "instance", Value: "internal"}}},
+	}
+	for i, v := range []float64{42, 2, 3, 0.25, 3} {
+		want[i].Samples = append([]remotewrite.Sample{}, sample...)
+		want[i].Samples[0].Value = v
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestClient(t *testing.T) {
+	var (
+		status   = http.StatusServiceUnavailable
+		received []remotewrite.TimeSeries
+	)
+	receiver := remotewritetest.Receiver(func(s []remotewrite.TimeSeries) { received = s })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusNoContent {
+			http.Error(w, "unavailable", status)
+			return
+		}
+		receiver.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	c := remotewrite.NewClient(server.URL, server.Client(), "test")
+	series := []remotewrite.TimeSeries{{Labels: []remotewrite.Label{{Name: "__name__", Value: "bind_up"}}, Samples: []remotewrite.Sample{{Value: 1, Timestamp: 1}}}}
+	req := remotewrite.Encode(remotewrite.Marshal(series))
+
+	var recoverable remotewrite.RecoverableError
+	if err := c.Store(context.Background(), req); !errors.As(err, &recoverable) {
+		t.Errorf("expected recoverable error for 503, got %v", err)
+	}
+	status = http.StatusBadRequest
+	if err := c.Store(context.Background(), req); err == nil || errors.As(err, &recoverable) {
+		t.Errorf("expected unrecoverable error for 400, got %v", err)
+	}
+	status = http.StatusNoContent
+	if err := c.Store(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(received, series) {
+		t.Errorf("received %v, want %v", received, series)
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	dir := t.TempDir()
+	b, err := remotewrite.NewBuffer(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{0, 0, 1} {
+		if n, err := b.Push([]byte{byte(i)}); err != nil || n != want {
+			t.Errorf("push %d: dropped %d, %v, want %d", i, n, err, want)
+		}
+	}
+
+	// The requests left are loaded again, oldest first.
+	b, err = remotewrite.NewBuffer(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := b.Len(); n != 2 {
+		t.Fatalf("got %d buffered requests, want 2", n)
+	}
+	for _, want := range []byte{1, 2} {
+		if got := b.Peek(); len(got) != 1 || got[0] != want {
+			t.Errorf("got request %v, want %d", got, want)
+		}
+		if err := b.Pop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.Peek() != nil {
+		t.Error("expected empty buffer")
+	}
+	if _, err := b.Push([]byte{3}); err != nil {
+		t.Fatal(err)
+	}
+	b, err = remotewrite.NewBuffer(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Peek(); len(got) != 1 || got[0] != 3 {
+		t.Errorf("got request %v after reopening, want 3", got)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rndc
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// algorithm identifies an HMAC algorithm using BIND's DST algorithm numbers,
+// which are also used on the wire.
+type algorithm byte
+
+const (
+	algHMACMD5    algorithm = 157
+	algHMACSHA1   algorithm = 161
+	algHMACSHA224 algorithm = 162
+	algHMACSHA256 algorithm = 163
+	algHMACSHA384 algorithm = 164
+	algHMACSHA512 algorithm = 165
+)
+
+var algorithms = map[string]algorithm{
+	"hmac-md5":    algHMACMD5,
+	"hmac-sha1":   algHMACSHA1,
+	"hmac-sha224": algHMACSHA224,
+	"hmac-sha256": algHMACSHA256,
+	"hmac-sha384": algHMACSHA384,
+	"hmac-sha512": algHMACSHA512,
+}
+
+func (a algorithm) hash() func() hash.Hash {
+	switch a {
+	case algHMACMD5:
+		return md5.New
+	case algHMACSHA1:
+		return sha1.New
+	case algHMACSHA224:
+		return sha256.New224
+	case algHMACSHA384:
+		return sha512.New384
+	case algHMACSHA512:
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+// Key is a shared secret authenticating control channel messages.
+type Key struct {
+	Name      string
+	Algorithm string
+	Secret    []byte
+
+	algorithm algorithm
+}
+
+var (
+	keyRE       = regexp.MustCompile(`key\s+"?([^"\s{]+)"?\s*\{`)
+	algorithmRE = regexp.MustCompile(`algorithm\s+"?([\w.-]+)"?\s*;`)
+	secretRE    = regexp.MustCompile(`secret\s+"([^"]+)"\s*;`)
+)
+
+// NewKey returns a Key for the given algorithm name, e.g. "hmac-sha256".
+func NewKey(name, alg string, secret []byte) (Key, error) {
+	// Older configurations use the full TSIG algorithm names, e.g.
+	// "hmac-md5.sig-alg.reg.int".
+	a, ok := algorithms[strings.ToLower(strings.SplitN(alg, ".", 2)[0])]
+	if !ok {
+		return Key{}, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return Key{Name: name, Algorithm: alg, Secret: secret, algorithm: a}, nil
+}
+
+// ReadKeyFile reads the first key from a file in the format written by
+// rndc-confgen, e.g. rndc.key.
+func ReadKeyFile(path string) (Key, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+	k, err := ParseKey(string(b))
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid key file %s: %s", path, err)
+	}
+	return k, nil
+}
+
+// ParseKey parses the first key statement in s.
+func ParseKey(s string) (Key, error) {
+	m := keyRE.FindStringSubmatchIndex(s)
+	if m == nil {
+		return Key{}, fmt.Errorf("no key statement found")
+	}
+	name := s[m[2]:m[3]]
+	body := s[m[1]:]
+	if i := strings.Index(body, "}"); i >= 0 {
+		body = body[:i]
+	}
+
+	alg := algorithmRE.FindStringSubmatch(body)
+	if alg == nil {
+		return Key{}, fmt.Errorf("key %q has no algorithm", name)
+	}
+	secret := secretRE.FindStringSubmatch(body)
+	if secret == nil {
+		return Key{}, fmt.Errorf("key %q has no secret", name)
+	}
+	b, err := base64.StdEncoding.DecodeString(secret[1])
+	if err != nil {
+		return Key{}, fmt.Errorf("key %q has an invalid secret: %s", name, err)
+	}
+	return NewKey(name, alg[1], b)
+}
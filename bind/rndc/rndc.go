@@ -0,0 +1,219 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rndc implements a client for BIND's control channel, as used by
+// the rndc utility.
+package rndc
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAddress is the default address of the control channel.
+const DefaultAddress = "127.0.0.1:953"
+
+// Client sends commands to BIND's control channel.
+type Client struct {
+	addr    string
+	key     Key
+	timeout time.Duration
+}
+
+// NewClient returns an initialized Client. Every command, including
+// connecting, has to finish within the given timeout.
+func NewClient(addr string, key Key, timeout time.Duration) *Client {
+	return &Client{
+		addr:    addr,
+		key:     key,
+		timeout: timeout,
+	}
+}
+
+// Command runs a command, e.g. "status", and returns its output.
+func (c *Client) Command(command string) (string, error) {
+	conn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return "", fmt.Errorf("error connecting to control channel: %s", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return "", err
+	}
+
+	// The first message of a connection only establishes the nonce
+	// which has to be included in the actual command.
+	serial := rand.Uint32()
+	resp, err := c.roundTrip(conn, request(serial, "null", ""))
+	if err != nil {
+		return "", err
+	}
+	nonce := resp.table("_ctrl").str("_nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("no nonce received from control channel")
+	}
+
+	resp, err = c.roundTrip(conn, request(serial+1, command, nonce))
+	if err != nil {
+		return "", err
+	}
+	data := resp.table("_data")
+	if e := data.str("err"); e != "" {
+		return "", fmt.Errorf("command %q failed: %s", command, e)
+	}
+	return data.str("text"), nil
+}
+
+func (c *Client) roundTrip(conn net.Conn, msg table) (table, error) {
+	b, err := encode(msg, c.key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(b); err != nil {
+		return nil, fmt.Errorf("error sending command: %s", err)
+	}
+	resp, err := decode(conn, c.key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %s", err)
+	}
+	return resp, nil
+}
+
+// request returns a command message. A nonce is required for all but the
+// first message of a connection.
+func request(serial uint32, command, nonce string) table {
+	now := time.Now().Unix()
+	ctrl := table{
+		{"_ser", strconv.FormatUint(uint64(serial), 10)},
+		{"_tim", strconv.FormatInt(now, 10)},
+		{"_exp", strconv.FormatInt(now+60, 10)},
+	}
+	if nonce != "" {
+		ctrl = append(ctrl, entry{"_nonce", nonce})
+	}
+	return table{
+		{"_ctrl", ctrl},
+		{"_data", table{{"type", command}}},
+	}
+}
+
+// Status is the server status reported by the status command.
+type Status struct {
+	Version        string
+	WorkerThreads  uint64
+	Zones          uint64
+	AutomaticZones uint64
+	XfersRunning   uint64
+	XfersDeferred  uint64
+	SOAQueries     uint64
+
+	RecursiveClients          uint64
+	RecursiveClientsSoftLimit uint64
+	RecursiveClientsHardLimit uint64
+	TCPClients                uint64
+	TCPClientsLimit           uint64
+
+	// Running is true while the server isn't shutting down.
+	Running bool
+	// Reloading is true while a reload or reconfiguration is in progress.
+	Reloading bool
+}
+
+// Status runs the status command and parses its output.
+func (c *Client) Status() (Status, error) {
+	text, err := c.Command("status")
+	if err != nil {
+		return Status{}, err
+	}
+	return ParseStatus(text)
+}
+
+// ParseStatus parses the output of the status command.
+func ParseStatus(text string) (Status, error) {
+	var s Status
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "server is up and running":
+			s.Running = true
+			continue
+		case strings.HasPrefix(line, "reload") && strings.HasSuffix(line, "in progress"):
+			s.Running = true
+			s.Reloading = true
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		var err error
+		switch key {
+		case "version":
+			s.Version = value
+		case "worker threads":
+			s.WorkerThreads, err = parseUint(value)
+		case "number of zones":
+			// e.g. "102 (97 automatic)"
+			n, auto, _ := strings.Cut(value, " (")
+			if s.Zones, err = parseUint(n); err == nil && auto != "" {
+				s.AutomaticZones, err = parseUint(strings.TrimSuffix(auto, " automatic)"))
+			}
+		case "xfers running":
+			s.XfersRunning, err = parseUint(value)
+		case "xfers deferred":
+			s.XfersDeferred, err = parseUint(value)
+		case "soa queries in progress":
+			s.SOAQueries, err = parseUint(value)
+		case "recursive clients":
+			// current/soft/hard
+			var v []uint64
+			if v, err = parseUints(value, 3); err == nil {
+				s.RecursiveClients, s.RecursiveClientsSoftLimit, s.RecursiveClientsHardLimit = v[0], v[1], v[2]
+			}
+		case "tcp clients":
+			// current/limit
+			var v []uint64
+			if v, err = parseUints(value, 2); err == nil {
+				s.TCPClients, s.TCPClientsLimit = v[0], v[1]
+			}
+		}
+		if err != nil {
+			return s, fmt.Errorf("invalid status line %q: %s", line, err)
+		}
+	}
+	return s, nil
+}
+
+func parseUint(s string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+}
+
+func parseUints(s string, n int) ([]uint64, error) {
+	fields := strings.Split(s, "/")
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d values", n)
+	}
+	v := make([]uint64, n)
+	for i, f := range fields {
+		var err error
+		if v[i], err = parseUint(f); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rndc
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	keyFile = `key "rndc-key" {
+	algorithm hmac-sha256;
+	secret "c2VjcmV0IGtleSBmb3IgdGVzdGluZyBybmRj";
+};
+`
+	statusText = `version: BIND 9.18.12-1-Debian (Extended Support Version) <id:>
+running on localhost: Linux x86_64 6.1.0
+boot time: Sat, 15 Jul 2021 05:11:08 GMT
+last configured: Sat, 15 Jul 2021 05:11:08 GMT
+configuration file: /etc/bind/named.conf
+CPUs found: 4
+worker threads: 4
+UDP listeners per interface: 4
+number of zones: 102 (97 automatic)
+debug level: 0
+xfers running: 2
+xfers deferred: 1
+soa queries in progress: 3
+query logging is OFF
+recursive clients: 12/900/1000
+tcp clients: 5/150
+TCP high-water: 8
+server is up and running`
+)
+
+// fakeServer implements the server side of the control channel protocol.
+type fakeServer struct {
+	t        *testing.T
+	listener net.Listener
+	key      Key
+	text     string
+}
+
+func newFakeServer(t *testing.T, key Key, text string) *fakeServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{t: t, listener: l, key: key, text: text}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	const nonce = "2863311530"
+
+	for i := 0; ; i++ {
+		req, err := decode(conn, s.key)
+		if err != nil {
+			return
+		}
+		ctrl := req.table("_ctrl")
+		if ctrl.str("_ser") == "" || ctrl.str("_tim") == "" || ctrl.str("_exp") == "" {
+			s.t.Errorf("missing control fields in %v", ctrl)
+			return
+		}
+
+		data := table{{"type", req.table("_data").str("type")}, {"result", "0"}}
+		if i > 0 {
+			if got := ctrl.str("_nonce"); got != nonce {
+				s.t.Errorf("expected nonce %q, got %q", nonce, got)
+				return
+			}
+			if cmd := req.table("_data").str("type"); cmd == "status" {
+				data = append(data, entry{"text", s.text})
+			} else {
+				data = append(data, entry{"err", "unknown command"})
+			}
+		}
+		resp, err := encode(table{
+			{"_ctrl", table{{"_rpl", "1"}, {"_ser", ctrl.str("_ser")}, {"_nonce", nonce}}},
+			{"_data", data},
+		}, s.key)
+		if err != nil {
+			s.t.Error(err)
+			return
+		}
+		if _, err := conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	for _, alg := range []string{"hmac-md5", "hmac-sha1", "hmac-sha256", "hmac-sha512"} {
+		key, err := NewKey("rndc-key", alg, []byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := newFakeServer(t, key, statusText)
+
+		status, err := NewClient(s.listener.Addr().String(), key, time.Second).Status()
+		if err != nil {
+			t.Fatalf("%s: %s", alg, err)
+		}
+		want := Status{
+			Version:                   "BIND 9.18.12-1-Debian (Extended Support Version) <id:>",
+			WorkerThreads:             4,
+			Zones:                     102,
+			AutomaticZones:            97,
+			XfersRunning:              2,
+			XfersDeferred:             1,
+			SOAQueries:                3,
+			RecursiveClients:          12,
+			RecursiveClientsSoftLimit: 900,
+			RecursiveClientsHardLimit: 1000,
+			TCPClients:                5,
+			TCPClientsLimit:           150,
+			Running:                   true,
+		}
+		if !reflect.DeepEqual(status, want) {
+			t.Errorf("%s: expected %+v, got %+v", alg, want, status)
+		}
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	key, err := ParseKey(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newFakeServer(t, key, statusText)
+
+	if _, err := NewClient(s.listener.Addr().String(), key, time.Second).Command("foo"); err == nil {
+		t.Error("expected error for unknown command")
+	}
+
+	other, _ := NewKey("rndc-key", "hmac-sha256", []byte("other"))
+	if _, err := NewClient(s.listener.Addr().String(), other, time.Second).Status(); err == nil {
+		t.Error("expected error for wrong key")
+	}
+}
+
+func TestAuthLayout(t *testing.T) {
+	// The _auth section has a fixed layout, named locates the signature
+	// at a fixed offset.
+	for alg, prefix := range map[string][]byte{
+		"hmac-md5": {
+			0x05, '_', 'a', 'u', 't', 'h', typeTable, 0x00, 0x00, 0x00, 0x20,
+			0x04, 'h', 'm', 'd', '5', typeBinary, 0x00, 0x00, 0x00, 0x16,
+		},
+		"hmac-sha256": {
+			0x05, '_', 'a', 'u', 't', 'h', typeTable, 0x00, 0x00, 0x00, 0x63,
+			0x04, 'h', 's', 'h', 'a', typeBinary, 0x00, 0x00, 0x00, 0x59, byte(algHMACSHA256),
+		},
+	} {
+		key, _ := NewKey("rndc-key", alg, []byte("secret"))
+		b, err := encode(request(1, "status", ""), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(b[8:], prefix) {
+			t.Errorf("%s: unexpected _auth section % x", alg, b[8:8+len(prefix)])
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey("# generated by rndc-confgen\n" + keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Name != "rndc-key" || key.Algorithm != "hmac-sha256" || string(key.Secret) != "secret key for testing rndc" {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	if _, err := ParseKey(`key "k" { algorithm hmac-foo; secret "c2VjcmV0"; };`); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rndc
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Value types of the ISC command channel (isccc) wire format.
+const (
+	typeString = 0x00
+	typeBinary = 0x01
+	typeTable  = 0x02
+	typeList   = 0x03
+)
+
+const (
+	protocolVersion = 1
+	// Maximum accepted message size, as enforced by named.
+	maxMessageSize = 128 * 1024
+
+	// Lengths of the base64 encoded signatures in the _auth section.
+	hmd5Length = 22
+	hshaLength = 88
+)
+
+var errBadAuth = errors.New("bad auth")
+
+// entry is a key-value pair of a table. Values are []byte, table or list.
+type entry struct {
+	key   string
+	value interface{}
+}
+
+// table is an ordered association list, the top-level structure of every
+// message.
+type table []entry
+
+// list is a sequence of values.
+type list []interface{}
+
+func (t table) lookup(key string) interface{} {
+	for _, e := range t {
+		if e.key == key {
+			return e.value
+		}
+	}
+	return nil
+}
+
+// table returns the table stored under key, or nil.
+func (t table) table(key string) table {
+	v, _ := t.lookup(key).(table)
+	return v
+}
+
+// str returns the string stored under key, or an empty string.
+func (t table) str(key string) string {
+	v, _ := t.lookup(key).([]byte)
+	return string(v)
+}
+
+func (t table) marshal(b *bytes.Buffer) error {
+	for _, e := range t {
+		if len(e.key) > 255 {
+			return fmt.Errorf("key %q too long", e.key)
+		}
+		b.WriteByte(byte(len(e.key)))
+		b.WriteString(e.key)
+		if err := marshalValue(b, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshalValue(b *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case []byte:
+		b.WriteByte(typeBinary)
+		writeUint32(b, uint32(len(v)))
+		b.Write(v)
+	case string:
+		return marshalValue(b, []byte(v))
+	case table, list:
+		t := byte(typeTable)
+		if _, ok := v.(list); ok {
+			t = typeList
+		}
+		b.WriteByte(t)
+		start := b.Len()
+		writeUint32(b, 0)
+		if t == typeTable {
+			if err := v.(table).marshal(b); err != nil {
+				return err
+			}
+		} else {
+			for _, e := range v.(list) {
+				if err := marshalValue(b, e); err != nil {
+					return err
+				}
+			}
+		}
+		binary.BigEndian.PutUint32(b.Bytes()[start:], uint32(b.Len()-start-4))
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
+
+func writeUint32(b *bytes.Buffer, v uint32) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	b.Write(buf[:])
+}
+
+// unmarshalTable decodes the table encoded in b.
+func unmarshalTable(b []byte) (table, error) {
+	var t table
+	for len(b) > 0 {
+		n := int(b[0])
+		if len(b) < 1+n {
+			return nil, io.ErrUnexpectedEOF
+		}
+		key := string(b[1 : 1+n])
+		v, rest, err := unmarshalValue(b[1+n:])
+		if err != nil {
+			return nil, err
+		}
+		t = append(t, entry{key: key, value: v})
+		b = rest
+	}
+	return t, nil
+}
+
+// unmarshalValue decodes the value at the start of b and returns the
+// remaining bytes.
+func unmarshalValue(b []byte) (interface{}, []byte, error) {
+	if len(b) < 5 {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	t, n := b[0], binary.BigEndian.Uint32(b[1:5])
+	b = b[5:]
+	if uint64(len(b)) < uint64(n) {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	data, rest := b[:n], b[n:]
+
+	switch t {
+	case typeString, typeBinary:
+		return data, rest, nil
+	case typeTable:
+		v, err := unmarshalTable(data)
+		return v, rest, err
+	case typeList:
+		var l list
+		for len(data) > 0 {
+			v, r, err := unmarshalValue(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			l = append(l, v)
+			data = r
+		}
+		return l, rest, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown value type %d", t)
+	}
+}
+
+// encode returns the framed wire representation of msg, authenticated with
+// the given key.
+func encode(msg table, key Key) ([]byte, error) {
+	var body bytes.Buffer
+	if err := msg.marshal(&body); err != nil {
+		return nil, err
+	}
+
+	var auth table
+	sig := key.sign(body.Bytes())
+	if key.algorithm == algHMACMD5 {
+		auth = table{{"hmd5", sig[:hmd5Length]}}
+	} else {
+		auth = table{{"hsha", append([]byte{byte(key.algorithm)}, sig[:hshaLength]...)}}
+	}
+
+	var b bytes.Buffer
+	writeUint32(&b, 0)
+	writeUint32(&b, protocolVersion)
+	if err := (table{{"_auth", auth}}).marshal(&b); err != nil {
+		return nil, err
+	}
+	b.Write(body.Bytes())
+	binary.BigEndian.PutUint32(b.Bytes(), uint32(b.Len()-4))
+	return b.Bytes(), nil
+}
+
+// decode reads a framed message from r and verifies its signature.
+func decode(r io.Reader, key Key) (table, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if n < 4 || n > maxMessageSize {
+		return nil, fmt.Errorf("invalid message length %d", n)
+	}
+	if v := binary.BigEndian.Uint32(hdr[4:]); v != protocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %d", v)
+	}
+	b := make([]byte, n-4)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+
+	// The signature covers everything after the _auth section, which has
+	// to be the first entry of the message.
+	if len(b) < 1 || len(b) < 1+int(b[0]) || string(b[1:1+int(b[0])]) != "_auth" {
+		return nil, errBadAuth
+	}
+	authValue, signed, err := unmarshalValue(b[1+int(b[0]):])
+	if err != nil {
+		return nil, err
+	}
+	auth, ok := authValue.(table)
+	if !ok {
+		return nil, errBadAuth
+	}
+	sig := key.sign(signed)
+	if key.algorithm == algHMACMD5 {
+		if !hmac.Equal([]byte(auth.str("hmd5")), sig[:hmd5Length]) {
+			return nil, errBadAuth
+		}
+	} else {
+		hsha := []byte(auth.str("hsha"))
+		if len(hsha) != 1+hshaLength || hsha[0] != byte(key.algorithm) || !hmac.Equal(hsha[1:], sig[:hshaLength]) {
+			return nil, errBadAuth
+		}
+	}
+
+	return unmarshalTable(signed)
+}
+
+// sign returns the base64 encoded HMAC of b, zero padded to the length of
+// the largest signature.
+func (k Key) sign(b []byte) []byte {
+	mac := hmac.New(k.algorithm.hash(), k.Secret)
+	mac.Write(b)
+	sum := mac.Sum(nil)
+
+	sig := make([]byte, hshaLength)
+	base64.StdEncoding.Encode(sig, sum)
+	return sig
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsfile
+
+// The statistics file uses human-readable descriptions for counters, these
+// tables map them to the names used by the statistics channel.
+var (
+	nsstats = map[string]string{
+		"IPv4 requests received":                            "Requestv4",
+		"IPv6 requests received":                            "Requestv6",
+		"requests with EDNS(0) received":                    "ReqEdns0",
+		"requests with unsupported EDNS version received":   "ReqBadEDNSVer",
+		"requests with TSIG received":                       "ReqTSIG",
+		"requests with SIG(0) received":                     "ReqSIG0",
+		"requests with invalid signature":                   "ReqBadSIG",
+		"TCP requests received":                             "ReqTCP",
+		"auth queries rejected":                             "AuthQryRej",
+		"recursive queries rejected":                        "RecQryRej",
+		"transfer requests rejected":                        "XfrRej",
+		"update requests rejected":                          "UpdateRej",
+		"responses sent":                                    "Response",
+		"truncated responses sent":                          "TruncatedResp",
+		"responses with EDNS(0) sent":                       "RespEDNS0",
+		"responses with TSIG sent":                          "RespTSIG",
+		"responses with SIG(0) sent":                        "RespSIG0",
+		"queries resulted in successful answer":             "QrySuccess",
+		"queries resulted in authoritative answer":          "QryAuthAns",
+		"queries resulted in non authoritative answer":      "QryNoauthAns",
+		"queries resulted in referral answer":               "QryReferral",
+		"queries resulted in nxrrset":                       "QryNxrrset",
+		"queries resulted in SERVFAIL":                      "QrySERVFAIL",
+		"queries resulted in FORMERR":                       "QryFORMERR",
+		"queries resulted in NXDOMAIN":                      "QryNXDOMAIN",
+		"queries caused recursion":                          "QryRecursion",
+		"duplicate queries received":                        "QryDuplicate",
+		"queries dropped":                                   "QryDropped",
+		"other query failures":                              "QryFailure",
+		"requested transfers completed":                     "XfrReqDone",
+		"update requests forwarded":                         "UpdateReqFwd",
+		"update responses forwarded":                        "UpdateRespFwd",
+		"update forward failed":                             "UpdateFwdFail",
+		"updates completed":                                 "UpdateDone",
+		"updates failed":                                    "UpdateFail",
+		"updates rejected due to prerequisite failure":      "UpdateBadPrereq",
+		"recursing clients":                                 "RecursClients",
+		"responses dropped for rate limits":                 "RateDropped",
+		"responses truncated for rate limits":               "RateSlipped",
+		"response policy zone rewrites":                     "RPZRewrites",
+		"UDP queries received":                              "QryUDP",
+		"TCP queries received":                              "QryTCP",
+		"NSID option received":                              "NSIDOpt",
+		"Expire option received":                            "ExpireOpt",
+		"Other EDNS option received":                        "OtherOpt",
+		"COOKIE option received":                            "CookieIn",
+		"COOKIE - client only":                              "CookieNew",
+		"COOKIE - bad size":                                 "CookieBadSize",
+		"COOKIE - bad time":                                 "CookieBadTime",
+		"COOKIE - no match":                                 "CookieNoMatch",
+		"COOKIE - match":                                    "CookieMatch",
+		"EDNS client subnet option received":                "ECSOpt",
+		"queries resulted in NXDOMAIN that were redirected": "QryNXRedir",
+		"queries resulted in NXDOMAIN that were redirected and resulted in a successful remote lookup": "QryNXRedirRLookup",
+		"sent badcookie":                                           "QryBADCOOKIE",
+		"Keytag option received":                                   "KeyTagOpt",
+		"TCP connection high-water":                                "TCPConnHighWater",
+		"Update quota exceeded":                                    "UpdateQuota",
+		"queries dropped due to recursive client limit":            "RecLimitDropped",
+		"queries triggered prefetch":                               "Prefetch",
+		"attempts to use stale cache data after lookup failure":    "QryTryStale",
+		"successful uses of stale cache data after lookup failure": "QryUsedStale",
+	}
+
+	zonestats = map[string]string{
+		"IPv4 notifies sent":          "NotifyOutv4",
+		"IPv6 notifies sent":          "NotifyOutv6",
+		"IPv4 notifies received":      "NotifyInv4",
+		"IPv6 notifies received":      "NotifyInv6",
+		"notifies rejected":           "NotifyRej",
+		"IPv4 SOA queries sent":       "SOAOutv4",
+		"IPv6 SOA queries sent":       "SOAOutv6",
+		"IPv4 AXFR requested":         "AXFRReqv4",
+		"IPv6 AXFR requested":         "AXFRReqv6",
+		"IPv4 IXFR requested":         "IXFRReqv4",
+		"IPv6 IXFR requested":         "IXFRReqv6",
+		"transfer requests succeeded": "XfrSuccess",
+		"transfer requests failed":    "XfrFail",
+	}
+
+	resstats = map[string]string{
+		"IPv4 queries sent":                         "Queryv4",
+		"IPv6 queries sent":                         "Queryv6",
+		"IPv4 responses received":                   "Responsev4",
+		"IPv6 responses received":                   "Responsev6",
+		"NXDOMAIN received":                         "NXDOMAIN",
+		"SERVFAIL received":                         "SERVFAIL",
+		"FORMERR received":                          "FORMERR",
+		"REFUSED received":                          "REFUSED",
+		"other errors received":                     "OtherError",
+		"EDNS(0) query failures":                    "EDNS0Fail",
+		"mismatch responses received":               "Mismatch",
+		"truncated responses received":              "Truncated",
+		"lame delegations received":                 "Lame",
+		"query retries":                             "Retry",
+		"queries aborted due to quota":              "QueryAbort",
+		"failures in opening query sockets":         "QuerySockFail",
+		"UDP queries in progress":                   "QueryCurUDP",
+		"TCP queries in progress":                   "QueryCurTCP",
+		"query timeouts":                            "QueryTimeout",
+		"IPv4 NS address fetches":                   "GlueFetchv4",
+		"IPv6 NS address fetches":                   "GlueFetchv6",
+		"IPv4 NS address fetch failed":              "GlueFetchv4Fail",
+		"IPv6 NS address fetch failed":              "GlueFetchv6Fail",
+		"DNSSEC validation attempted":               "ValAttempt",
+		"DNSSEC validation succeeded":               "ValOk",
+		"DNSSEC NX validation succeeded":            "ValNegOk",
+		"DNSSEC validation failed":                  "ValFail",
+		"queries with RTT < 10ms":                   "QryRTT10",
+		"queries with RTT 10-100ms":                 "QryRTT100",
+		"queries with RTT 100-500ms":                "QryRTT500",
+		"queries with RTT 500-800ms":                "QryRTT800",
+		"queries with RTT 800-1600ms":               "QryRTT1600",
+		"queries with RTT > 1600ms":                 "QryRTT1600+",
+		"active fetches":                            "NumFetch",
+		"bucket size":                               "BucketSize",
+		"COOKIE send with client cookie only":       "ClientCookieOut",
+		"COOKIE sent with client and server cookie": "ServerCookieOut",
+		"COOKIE replies received":                   "CookieIn",
+		"COOKIE client ok":                          "CookieClientOk",
+		"bad EDNS version":                          "BadEDNSVersion",
+		"bad cookie rcode":                          "BadCookieRcode",
+		"spilled due to zone quota":                 "ZoneQuota",
+		"spilled due to server quota":               "ServerQuota",
+		"waited for next item":                      "NextItem",
+		"priming queries":                           "Priming",
+	}
+
+	sockPrefixes = map[string]string{
+		"UDP/IPv4":    "UDP4",
+		"UDP/IPv6":    "UDP6",
+		"TCP/IPv4":    "TCP4",
+		"TCP/IPv6":    "TCP6",
+		"Unix domain": "Unix",
+		"FDwatch":     "FDwatch",
+		"Raw":         "Raw",
+	}
+
+	sockSuffixes = map[string]string{
+		"sockets opened":             "Open",
+		"socket open failures":       "OpenFail",
+		"sockets closed":             "Close",
+		"socket bind failures":       "BindFail",
+		"socket connect failures":    "ConnFail",
+		"connections established":    "Conn",
+		"connections accepted":       "Accept",
+		"connection accept failures": "AcceptFail",
+		"send errors":                "SendErr",
+		"recv errors":                "RecvErr",
+		"sockets active":             "Active",
+	}
+)
@@ -0,0 +1,281 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statsfile implements a bind.Client parsing the statistics file
+// written by `rndc stats`.
+package statsfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+const (
+	dumpStart = "+++ Statistics Dump +++"
+	dumpEnd   = "--- Statistics Dump ---"
+
+	defaultView = "_default"
+)
+
+// Client implements bind.Client and reads the newest complete statistics dump
+// from a BIND statistics file (named.stats).
+type Client struct {
+	path    string
+	command []string
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewClient returns an initialized Client. If command is not empty, it is
+// run before every read to trigger a new dump, e.g. "rndc stats". The command
+// is killed after the given timeout.
+func NewClient(logger *slog.Logger, path string, command []string, timeout time.Duration) *Client {
+	return &Client{
+		path:    path,
+		command: command,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Stats implements bind.Stats.
+func (c *Client) Stats(groups ...bind.StatisticGroup) (bind.Statistics, error) {
+	s := bind.Statistics{}
+	m := map[bind.StatisticGroup]bool{}
+	for _, g := range groups {
+		m[g] = true
+		// The dump only has the server and view counters, e.g. no zone
+		// serials or task manager.
+		if g != bind.ServerStats && g != bind.ViewStats {
+			s.Unsupported = append(s.Unsupported, g)
+		}
+	}
+	if !m[bind.ServerStats] && !m[bind.ViewStats] {
+		return s, nil
+	}
+
+	if len(c.command) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, c.command[0], c.command[1:]...).CombinedOutput(); err != nil {
+			return s, fmt.Errorf("error running %q: %s: %s", strings.Join(c.command, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	f, err := os.Open(c.path)
+	if err != nil {
+		return s, fmt.Errorf("error opening statistics file: %s", err)
+	}
+	defer f.Close()
+
+	// The file grows with every dump, only read the last complete one.
+	fi, err := f.Stat()
+	if err != nil {
+		return s, fmt.Errorf("error reading statistics file: %s", err)
+	}
+	offset, err := lastDump(f, fi.Size())
+	if err != nil {
+		return s, fmt.Errorf("error reading statistics file: %s", err)
+	}
+	stats, err := Parse(c.logger, io.NewSectionReader(f, offset, fi.Size()-offset))
+	if err != nil {
+		return s, fmt.Errorf("failed to parse statistics file %s: %s", c.path, err)
+	}
+	if m[bind.ServerStats] {
+		s.Server = stats.Server
+	}
+	if m[bind.ViewStats] {
+		s.Views = stats.Views
+	}
+	return s, nil
+}
+
+// lastDump returns the offset of the start of the last complete dump in r,
+// whose size is given, by searching backwards for the markers. It returns 0 if
+// there is no complete dump.
+func lastDump(r io.ReaderAt, size int64) (int64, error) {
+	const chunk = 64 * 1024
+	marker := dumpEnd
+	buf := make([]byte, chunk+len(dumpStart))
+	for end := size; end > 0; {
+		start := max(end-chunk, 0)
+		// Overlap with the following chunk to find markers crossing the
+		// boundary, but only those starting in this one.
+		b := buf[:min(end+int64(len(marker))-1, size)-start]
+		if _, err := r.ReadAt(b, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for {
+			i := bytes.LastIndex(b, []byte(marker))
+			if i < 0 {
+				break
+			}
+			if marker == dumpStart {
+				return start + int64(i), nil
+			}
+			marker, b = dumpStart, b[:i]
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// Parse reads a statistics file and returns the statistics of the newest
+// complete dump in it. Lines which can't be parsed are skipped and logged.
+func Parse(logger *slog.Logger, r io.Reader) (bind.Statistics, error) {
+	var (
+		last    *bind.Statistics
+		current *parser
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, dumpStart):
+			current = &parser{}
+		case strings.HasPrefix(line, dumpEnd):
+			if current != nil {
+				last = &current.stats
+			}
+			current = nil
+		case current != nil:
+			if err := current.line(line); err != nil {
+				logger.Debug("Skipping line of statistics file", "err", err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return bind.Statistics{}, err
+	}
+	if last == nil {
+		return bind.Statistics{}, fmt.Errorf("no complete statistics dump found")
+	}
+	return *last, nil
+}
+
+// parser holds the state of a single statistics dump.
+type parser struct {
+	stats   bind.Statistics
+	section string
+	// view is the view of the current subsection, nil outside of views.
+	view *bind.View
+}
+
+func (p *parser) line(line string) error {
+	if strings.HasPrefix(line, "++ ") && strings.HasSuffix(line, " ++") {
+		p.section = strings.TrimSuffix(strings.TrimPrefix(line, "++ "), " ++")
+		p.view = nil
+		return nil
+	}
+	if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+		p.view = nil
+		if name, ok := strings.CutPrefix(line[1:len(line)-1], "View: "); ok {
+			// Cache DB headers include the cache name: "View: default (Cache: default)".
+			if i := strings.Index(name, " (Cache: "); i >= 0 {
+				name = name[:i]
+			}
+			p.view = p.findView(name)
+		}
+		return nil
+	}
+
+	value, desc, ok := strings.Cut(line, " ")
+	if !ok {
+		return fmt.Errorf("invalid line %q", line)
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value in line %q", line)
+	}
+	desc = strings.TrimSpace(desc)
+
+	server := &p.stats.Server
+	switch p.section {
+	case "Incoming Requests":
+		server.IncomingRequests = append(server.IncomingRequests, bind.Counter{Name: desc, Counter: n})
+	case "Incoming Queries":
+		server.IncomingQueries = append(server.IncomingQueries, bind.Counter{Name: desc, Counter: n})
+	case "Outgoing Rcodes":
+		server.ServerRcodes = append(server.ServerRcodes, bind.Counter{Name: desc, Counter: n})
+	case "Name Server Statistics":
+		server.NameServerStats = append(server.NameServerStats, bind.Counter{Name: name(nsstats, desc), Counter: n})
+	case "Zone Maintenance Statistics":
+		server.ZoneStatistics = append(server.ZoneStatistics, bind.Counter{Name: name(zonestats, desc), Counter: n})
+	case "Socket I/O Statistics":
+		server.SocketStatistics = append(server.SocketStatistics, bind.Counter{Name: sockstat(desc), Counter: n})
+	case "Outgoing Queries":
+		if p.view != nil {
+			p.view.ResolverQueries = append(p.view.ResolverQueries, bind.Counter{Name: desc, Counter: n})
+		}
+	case "Resolver Statistics":
+		if p.view != nil {
+			p.view.ResolverStats = append(p.view.ResolverStats, bind.Counter{Name: name(resstats, desc), Counter: n})
+		}
+	case "Cache DB RRsets":
+		if p.view != nil {
+			p.view.Cache = append(p.view.Cache, bind.Gauge{Name: desc, Gauge: n})
+		}
+	}
+	return nil
+}
+
+// findView returns the view with the given name, adding it if necessary.
+func (p *parser) findView(name string) *bind.View {
+	// The statistics file calls the default view "default", while the
+	// statistics channel uses its internal name.
+	if name == "default" {
+		name = defaultView
+	}
+	for i := range p.stats.Views {
+		if p.stats.Views[i].Name == name {
+			return &p.stats.Views[i]
+		}
+	}
+	p.stats.Views = append(p.stats.Views, bind.View{Name: name})
+	return &p.stats.Views[len(p.stats.Views)-1]
+}
+
+// name returns the statistics channel name of a counter description, or the
+// description itself if it is unknown.
+func name(names map[string]string, desc string) string {
+	if n, ok := names[desc]; ok {
+		return n
+	}
+	return desc
+}
+
+// sockstat returns the statistics channel name of a socket counter
+// description, e.g. "UDP4Open" for "UDP/IPv4 sockets opened".
+func sockstat(desc string) string {
+	for prefix, p := range sockPrefixes {
+		if rest, ok := strings.CutPrefix(desc, prefix+" "); ok {
+			if s, ok := sockSuffixes[rest]; ok {
+				return p + s
+			}
+		}
+	}
+	return desc
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  bind.Statistics
+		err   bool
+	}{
+		{
+			name: "counters",
+			input: `+++ Statistics Dump +++ (1626325868)
+++ Incoming Requests ++
+               37634 QUERY
+++ Name Server Statistics ++
+                1024 queries triggered prefetch
+                   7 some future counter
+++ Socket I/O Statistics ++
+                  12 UDP/IPv4 sockets opened
+++ Resolver Statistics ++
+[Common]
+                   5 mismatch responses received
+[View: default]
+                7596 SERVFAIL received
+++ Cache DB RRsets ++
+[View: default (Cache: default)]
+                   5 #A
+--- Statistics Dump --- (1626325868)
+`,
+			want: bind.Statistics{
+				Server: bind.Server{
+					IncomingRequests: []bind.Counter{{Name: "QUERY", Counter: 37634}},
+					NameServerStats: []bind.Counter{
+						{Name: "Prefetch", Counter: 1024},
+						{Name: "some future counter", Counter: 7},
+					},
+					SocketStatistics: []bind.Counter{{Name: "UDP4Open", Counter: 12}},
+				},
+				Views: []bind.View{{
+					Name:          "_default",
+					ResolverStats: []bind.Counter{{Name: "SERVFAIL", Counter: 7596}},
+					Cache:         []bind.Gauge{{Name: "#A", Gauge: 5}},
+				}},
+			},
+		},
+		{
+			name: "unknown lines",
+			input: `+++ Statistics Dump +++ (1626325868)
+++ Incoming Requests ++
+               37634 QUERY
+some text BIND added
+++ Incoming Queries ++
+                 n/a A
+                 128
+--- Statistics Dump --- (1626325868)
+`,
+			want: bind.Statistics{
+				Server: bind.Server{
+					IncomingRequests: []bind.Counter{{Name: "QUERY", Counter: 37634}},
+				},
+			},
+		},
+		{
+			name: "last complete dump",
+			input: `+++ Statistics Dump +++ (1626325800)
+++ Incoming Requests ++
+                  10 QUERY
+--- Statistics Dump --- (1626325800)
++++ Statistics Dump +++ (1626325868)
+++ Incoming Requests ++
+                  20 QUERY
+--- Statistics Dump --- (1626325868)
++++ Statistics Dump +++ (1626325900)
+++ Incoming Requests ++
+                  30 QUERY
+`,
+			want: bind.Statistics{
+				Server: bind.Server{
+					IncomingRequests: []bind.Counter{{Name: "QUERY", Counter: 20}},
+				},
+			},
+		},
+		{
+			name: "incomplete dump",
+			input: `+++ Statistics Dump +++ (1626325900)
+++ Incoming Requests ++
+                  30 QUERY
+`,
+			err: true,
+		},
+		{
+			name:  "empty",
+			input: "",
+			err:   true,
+		},
+	} {
+		got, err := Parse(promslog.NewNopLogger(), strings.NewReader(tc.input))
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLastDump(t *testing.T) {
+	dump := func(size int) string {
+		const start, end = "+++ Statistics Dump +++ (1626325800)\n", "--- Statistics Dump --- (1626325800)\n"
+		return start + strings.Repeat("x", max(size-len(start)-len(end), 0)) + end
+	}
+	const chunk = 64 * 1024
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{name: "empty", input: "", want: 0},
+		{name: "single", input: dump(100), want: 0},
+		{name: "second", input: dump(100) + dump(200), want: 100},
+		{name: "incomplete", input: dump(100) + "+++ Statistics Dump +++ (1626325900)\n", want: 0},
+		{name: "large", input: dump(3*chunk) + dump(3*chunk), want: 3 * chunk},
+		// The start marker of the last dump crosses the first chunk read.
+		{name: "boundary", input: dump(100) + dump(chunk+5), want: 100},
+		// The end marker of the last dump crosses the first chunk read.
+		{name: "end boundary", input: dump(100) + dump(100) + strings.Repeat("x", chunk-32), want: 100},
+	} {
+		got, err := lastDump(strings.NewReader(tc.input), int64(len(tc.input)))
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if got != int64(tc.want) {
+			t.Errorf("%s: got offset %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	c := NewClient(promslog.NewNopLogger(), "../../fixtures/statsfile/named.stats", nil, time.Second)
+	s, err := c.Stats(bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bind.Counter{{Name: "QUERY", Counter: 37634}}
+	if !reflect.DeepEqual(s.Server.IncomingRequests, want) {
+		t.Errorf("got incoming requests %+v, want %+v", s.Server.IncomingRequests, want)
+	}
+	if len(s.Views) != 0 {
+		t.Errorf("expected no views without the view group, got %+v", s.Views)
+	}
+
+	s, err = c.Stats(bind.ServerStats, bind.ViewStats, bind.ZoneStats, bind.TaskStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bind.StatisticGroup{bind.ZoneStats, bind.TaskStats}; !reflect.DeepEqual(s.Unsupported, want) {
+		t.Errorf("got unsupported groups %v, want %v", s.Unsupported, want)
+	}
+	if len(s.Views) == 0 {
+		t.Error("expected views with the view group")
+	}
+
+	// Groups without statistics in the file are reported without reading it.
+	s, err = NewClient(promslog.NewNopLogger(), "missing", nil, time.Second).Stats(bind.ZoneStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bind.StatisticGroup{bind.ZoneStats}; !reflect.DeepEqual(s.Unsupported, want) {
+		t.Errorf("got unsupported groups %v, want %v", s.Unsupported, want)
+	}
+}
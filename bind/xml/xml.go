@@ -15,10 +15,13 @@ package xml
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/prometheus-community/bind_exporter/bind"
@@ -33,22 +36,36 @@ const (
 	TasksPath = "/xml/v3/tasks"
 	// ZonesPath is the HTTP path of the v3 zones resource.
 	ZonesPath = "/xml/v3/zones"
+	// XfrinsPath is the HTTP path of the v3 incoming zone transfers resource.
+	XfrinsPath = "/xml/v3/xfrins"
+	// NetPath is the HTTP path of the v3 network resource.
+	NetPath = "/xml/v3/net"
 
-	nsstat   = "nsstat"
-	opcode   = "opcode"
-	qtype    = "qtype"
-	resqtype = "resqtype"
-	resstats = "resstats"
-	zonestat = "zonestat"
-	rcode    = "rcode"
+	dnssecSign    = "dnssec-sign"
+	dnssecRefresh = "dnssec-refresh"
+	nsstat        = "nsstat"
+	opcode        = "opcode"
+	qtype         = "qtype"
+	resqtype      = "resqtype"
+	resstats      = "resstats"
+	sockstat      = "sockstat"
+	zonestat      = "zonestat"
+	rcode         = "rcode"
 )
 
+// Statistics is the statistics document of the XML v3 API.
+//
+// Deprecated: Client decodes the statistics documents while they are read
+// and no longer uses this type. It is kept for compatibility.
 type Statistics struct {
 	Server  Server           `xml:"server"`
 	Taskmgr bind.TaskManager `xml:"taskmgr"`
 	Views   []View           `xml:"views>view"`
 }
 
+// ZoneStatistics is the zones document of the XML v3 API.
+//
+// Deprecated: Client no longer uses this type. It is kept for compatibility.
 type ZoneStatistics struct {
 	ZoneViews []ZoneView `xml:"views>view"`
 }
@@ -56,6 +73,7 @@ type ZoneStatistics struct {
 type Server struct {
 	BootTime   time.Time  `xml:"boot-time"`
 	ConfigTime time.Time  `xml:"config-time"`
+	Version    string     `xml:"version"`
 	Counters   []Counters `xml:"counters"`
 }
 
@@ -65,11 +83,34 @@ type View struct {
 	Counters []Counters   `xml:"counters"`
 }
 
+// ZoneView is a view of ZoneStatistics.
+//
+// Deprecated: Client no longer uses this type. It is kept for compatibility.
 type ZoneView struct {
 	Name  string        `xml:"name,attr"`
 	Zones []ZoneCounter `xml:"zones>zone"`
 }
 
+type Transfer struct {
+	Name       string  `xml:"name,attr"`
+	Rdataclass string  `xml:"rdataclass,attr"`
+	State      string  `xml:"state"`
+	RemoteAddr string  `xml:"remoteaddr"`
+	XfrType    string  `xml:"xfrtype"`
+	Duration   float64 `xml:"duration"` // seconds
+	NMsg       uint64  `xml:"nmsg"`
+	NRecs      uint64  `xml:"nrecs"`
+	NBytes     uint64  `xml:"nbytes"`
+}
+
+type Socket struct {
+	ID           string   `xml:"id"`
+	References   uint64   `xml:"references"`
+	Type         string   `xml:"type"`
+	LocalAddress string   `xml:"local-address"`
+	States       []string `xml:"states>state"`
+}
+
 type Counters struct {
 	Type     string         `xml:"type,attr"`
 	Counters []bind.Counter `xml:"counter"`
@@ -81,15 +122,20 @@ type Counter struct {
 }
 
 type ZoneCounter struct {
-	Name       string `xml:"name,attr"`
-	Rdataclass string `xml:"rdataclass,attr"`
-	Serial     string `xml:"serial"`
+	Name       string     `xml:"name,attr"`
+	Rdataclass string     `xml:"rdataclass,attr"`
+	Serial     string     `xml:"serial"`
+	Counters   []Counters `xml:"counters"`
 }
 
 // Client implements bind.Client and can be used to query a BIND XML v3 API.
 type Client struct {
 	url  string
 	http *http.Client
+
+	// MaxResponseSize limits the size of a single statistics document. Zero
+	// means no limit.
+	MaxResponseSize int64
 }
 
 // NewClient returns an initialized Client.
@@ -104,27 +150,25 @@ func NewClient(url string, c *http.Client) *Client {
 // v. The endpoint must return a valid XML representation which can be
 // unmarshaled into the provided value.
 func (c *Client) Get(p string, v interface{}) error {
+	return c.stream(p, func(d *xml.Decoder) error {
+		return d.Decode(v)
+	})
+}
+
+// stream queries the given path and passes a decoder for the response to fn.
+func (c *Client) stream(p string, fn func(*xml.Decoder) error) error {
 	u, err := url.Parse(c.url)
 	if err != nil {
 		return fmt.Errorf("invalid URL %q: %s", c.url, err)
 	}
 	u.Path = path.Join(u.Path, p)
 
-	resp, err := c.http.Get(u.String())
-	if err != nil {
-		return fmt.Errorf("error querying stats: %s", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status %s", resp.Status)
-	}
-
-	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
-		return fmt.Errorf("failed to unmarshal XML response: %s", err)
-	}
-
-	return nil
+	return bind.Fetch(c.http, u.String(), c.MaxResponseSize, func(r io.Reader) error {
+		if err := fn(xml.NewDecoder(r)); err != nil {
+			return fmt.Errorf("failed to unmarshal XML response: %s", err)
+		}
+		return nil
+	})
 }
 
 // Stats implements bind.Stats.
@@ -135,74 +179,316 @@ func (c *Client) Stats(groups ...bind.StatisticGroup) (bind.Statistics, error) {
 		m[g] = true
 	}
 
-	var stats Statistics
-	var zonestats ZoneStatistics
 	if m[bind.ServerStats] || m[bind.ViewStats] {
-		if err := c.Get(ServerPath, &stats); err != nil {
+		handlers := map[string]handler{}
+		if m[bind.ServerStats] {
+			handlers["statistics/server"] = func(d *xml.Decoder, start xml.StartElement) error {
+				return decodeServer(d, start, &s)
+			}
+		}
+		if m[bind.ViewStats] {
+			handlers["statistics/views/view"] = func(d *xml.Decoder, start xml.StartElement) error {
+				v, err := decodeView(d, start)
+				s.Views = append(s.Views, v)
+				return err
+			}
+		}
+		if err := c.stream(ServerPath, func(d *xml.Decoder) error {
+			return walkStatistics(d, &s, handlers)
+		}); err != nil {
 			return s, err
 		}
+	}
 
-		s.Server.BootTime = stats.Server.BootTime
-		s.Server.ConfigTime = stats.Server.ConfigTime
-		for _, c := range stats.Server.Counters {
-			switch c.Type {
-			case opcode:
-				s.Server.IncomingRequests = c.Counters
-			case qtype:
-				s.Server.IncomingQueries = c.Counters
-			case nsstat:
-				s.Server.NameServerStats = c.Counters
-			case zonestat:
-				s.Server.ZoneStatistics = c.Counters
-			case rcode:
-				s.Server.ServerRcodes = c.Counters
-			}
+	if m[bind.ZoneStats] {
+		if err := c.stream(ZonesPath, func(d *xml.Decoder) error {
+			return walkStatistics(d, &s, map[string]handler{
+				"statistics/views/view": func(d *xml.Decoder, start xml.StartElement) error {
+					v, err := decodeZoneView(d, start)
+					s.ZoneViews = append(s.ZoneViews, v)
+					return err
+				},
+			})
+		}); err != nil {
+			return s, err
+		}
+	}
+
+	if m[bind.TransferStats] {
+		err := c.stream(XfrinsPath, func(d *xml.Decoder) error {
+			return walkStatistics(d, &s, map[string]handler{
+				"statistics/views/view": func(d *xml.Decoder, start xml.StartElement) error {
+					v, err := decodeTransferView(d, start)
+					s.TransferViews = append(s.TransferViews, v)
+					return err
+				},
+			})
+		})
+		if errors.Is(err, bind.ErrNotFound) {
+			s.Unsupported = append(s.Unsupported, bind.TransferStats)
+		} else if err != nil {
+			return s, err
+		}
+	}
+
+	if m[bind.ListenerStats] {
+		found := false
+		err := c.stream(NetPath, func(d *xml.Decoder) error {
+			return walkStatistics(d, &s, map[string]handler{
+				"statistics/socketmgr": func(d *xml.Decoder, start xml.StartElement) error {
+					found = true
+					var mgr struct {
+						Sockets []Socket `xml:"sockets>socket"`
+					}
+					if err := d.DecodeElement(&mgr, &start); err != nil {
+						return err
+					}
+					for _, sock := range mgr.Sockets {
+						s.Sockets = append(s.Sockets, bind.Socket{
+							ID:           sock.ID,
+							Type:         sock.Type,
+							LocalAddress: sock.LocalAddress,
+							References:   sock.References,
+							States:       sock.States,
+						})
+					}
+					return nil
+				},
+			})
+		})
+		switch {
+		case errors.Is(err, bind.ErrNotFound):
+			s.Unsupported = append(s.Unsupported, bind.ListenerStats)
+		case err != nil:
+			return s, err
+		case !found:
+			s.Unsupported = append(s.Unsupported, bind.ListenerStats)
+		}
+	}
+
+	if m[bind.TaskStats] {
+		err := c.stream(TasksPath, func(d *xml.Decoder) error {
+			return walkStatistics(d, &s, map[string]handler{
+				"statistics/taskmgr/thread-model": func(d *xml.Decoder, start xml.StartElement) error {
+					s.TaskManager = &bind.TaskManager{}
+					return d.DecodeElement(&s.TaskManager.ThreadModel, &start)
+				},
+				"statistics/loopmgr": func(d *xml.Decoder, start xml.StartElement) error {
+					s.LoopManager = &bind.LoopManager{}
+					return d.DecodeElement(s.LoopManager, &start)
+				},
+			})
+		})
+		switch {
+		case errors.Is(err, bind.ErrNotFound):
+			s.Unsupported = append(s.Unsupported, bind.TaskStats)
+		case err != nil:
+			return s, err
+		case s.TaskManager == nil && s.LoopManager == nil:
+			s.Unsupported = append(s.Unsupported, bind.TaskStats)
+		}
+	}
+
+	return s, nil
+}
+
+func decodeServer(d *xml.Decoder, start xml.StartElement, stats *bind.Statistics) error {
+	var server Server
+	if err := d.DecodeElement(&server, &start); err != nil {
+		return err
+	}
+
+	stats.Version = server.Version
+	s := &stats.Server
+
+	s.BootTime = server.BootTime
+	s.ConfigTime = server.ConfigTime
+	for _, c := range server.Counters {
+		switch c.Type {
+		case opcode:
+			s.IncomingRequests = c.Counters
+		case qtype:
+			s.IncomingQueries = c.Counters
+		case nsstat:
+			s.NameServerStats = c.Counters
+		case zonestat:
+			s.ZoneStatistics = c.Counters
+		case rcode:
+			s.ServerRcodes = c.Counters
+		case sockstat:
+			s.SocketStatistics = c.Counters
+		}
+	}
+	return nil
+}
+
+func decodeView(d *xml.Decoder, start xml.StartElement) (bind.View, error) {
+	var view View
+	if err := d.DecodeElement(&view, &start); err != nil {
+		return bind.View{}, err
+	}
+
+	v := bind.View{
+		Name:  view.Name,
+		Cache: view.Cache,
+	}
+	for _, c := range view.Counters {
+		switch c.Type {
+		case resqtype:
+			v.ResolverQueries = c.Counters
+		case resstats:
+			v.ResolverStats = c.Counters
 		}
+	}
+	return v, nil
+}
 
-		for _, view := range stats.Views {
-			v := bind.View{
-				Name:  view.Name,
-				Cache: view.Cache,
+func decodeZoneView(d *xml.Decoder, start xml.StartElement) (bind.ZoneView, error) {
+	v := bind.ZoneView{Name: attr(start, "name")}
+	err := walk(d, map[string]handler{
+		"zones/zone": func(d *xml.Decoder, start xml.StartElement) error {
+			var zone ZoneCounter
+			if err := d.DecodeElement(&zone, &start); err != nil {
+				return err
 			}
-			for _, c := range view.Counters {
-				switch c.Type {
-				case resqtype:
-					v.ResolverQueries = c.Counters
-				case resstats:
-					v.ResolverStats = c.Counters
+			if zone.Rdataclass == "IN" {
+				z := bind.ZoneCounter{
+					Name:   zone.Name,
+					Serial: zone.Serial,
+				}
+				for _, c := range zone.Counters {
+					switch c.Type {
+					case dnssecSign:
+						z.DNSSECSign = c.Counters
+					case dnssecRefresh:
+						z.DNSSECRefresh = c.Counters
+					}
 				}
+				v.ZoneData = append(v.ZoneData, z)
+			}
+			return nil
+		},
+	})
+	return v, err
+}
+
+func decodeTransferView(d *xml.Decoder, start xml.StartElement) (bind.TransferView, error) {
+	v := bind.TransferView{Name: attr(start, "name")}
+	err := walk(d, map[string]handler{
+		"xfrins/xfrin": func(d *xml.Decoder, start xml.StartElement) error {
+			var xfr Transfer
+			if err := d.DecodeElement(&xfr, &start); err != nil {
+				return err
+			}
+			if xfr.Rdataclass == "IN" {
+				v.Transfers = append(v.Transfers, bind.Transfer{
+					Zone:     xfr.Name,
+					Primary:  xfr.RemoteAddr,
+					State:    xfr.State,
+					Type:     xfr.XfrType,
+					Duration: time.Duration(xfr.Duration * float64(time.Second)),
+					Messages: xfr.NMsg,
+					Records:  xfr.NRecs,
+					Bytes:    xfr.NBytes,
+				})
+			}
+			return nil
+		},
+	})
+	return v, err
+}
+
+// handler consumes an element, including its end element.
+type handler func(*xml.Decoder, xml.StartElement) error
+
+// walk reads elements from d until the end of the document or of the element
+// it was called in. Elements are addressed by their slash-separated path
+// relative to the starting point, e.g. "statistics/views/view". An element
+// with a registered handler is passed to it, an element on the path to a
+// handler is descended into and all other elements are skipped without being
+// decoded.
+func walk(d *xml.Decoder, handlers map[string]handler) error {
+	prefixes := map[string]bool{}
+	for p := range handlers {
+		for i := range p {
+			if p[i] == '/' {
+				prefixes[p[:i]] = true
 			}
-			s.Views = append(s.Views, v)
 		}
 	}
 
-	if err := c.Get(ZonesPath, &zonestats); err != nil {
-		return s, err
+	var stack []string
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			p := t.Name.Local
+			if len(stack) > 0 {
+				p = stack[len(stack)-1] + "/" + p
+			}
+			if h, ok := handlers[p]; ok {
+				if err := h(d, t); err != nil {
+					return err
+				}
+			} else if prefixes[p] {
+				stack = append(stack, p)
+			} else if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil
+			}
+			stack = stack[:len(stack)-1]
+		}
 	}
+}
 
-	for _, view := range zonestats.ZoneViews {
-		v := bind.ZoneView{
-			Name: view.Name,
+// walkStatistics is walk for a statistics document. The version of the
+// document and the BIND version are recorded in s if present.
+func walkStatistics(d *xml.Decoder, s *bind.Statistics, handlers map[string]handler) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil
 		}
-		for _, zone := range view.Zones {
-			if zone.Rdataclass != "IN" {
-				continue
-			}
-			z := bind.ZoneCounter{
-				Name:   zone.Name,
-				Serial: zone.Serial,
+		if err != nil {
+			return err
+		}
+		if start, ok := t.(xml.StartElement); ok {
+			if start.Name.Local != "statistics" {
+				return d.Skip()
 			}
-			v.ZoneData = append(v.ZoneData, z)
+			s.SchemaVersion = attr(start, "version")
+			break
 		}
-		s.ZoneViews = append(s.ZoneViews, v)
 	}
 
-	if m[bind.TaskStats] {
-		if err := c.Get(TasksPath, &stats); err != nil {
-			return s, err
+	// Handlers are relative to the root element consumed above.
+	hs := map[string]handler{}
+	for p, h := range handlers {
+		hs[strings.TrimPrefix(p, "statistics/")] = h
+	}
+	if _, ok := hs["server"]; !ok {
+		hs["server/version"] = func(d *xml.Decoder, start xml.StartElement) error {
+			return d.DecodeElement(&s.Version, &start)
 		}
-		s.TaskManager = stats.Taskmgr
 	}
+	return walk(d, hs)
+}
 
-	return s, nil
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
 }
@@ -14,30 +14,45 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"math"
 	"net/http"
 	_ "net/http/pprof"
+	neturl "net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/dnstap"
+	"github.com/prometheus-community/bind_exporter/bind/file"
 	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/namedconf"
+	"github.com/prometheus-community/bind_exporter/bind/otlp"
+	"github.com/prometheus-community/bind_exporter/bind/probe"
+	"github.com/prometheus-community/bind_exporter/bind/remotewrite"
+	"github.com/prometheus-community/bind_exporter/bind/rndc"
+	"github.com/prometheus-community/bind_exporter/bind/statsfile"
 	"github.com/prometheus-community/bind_exporter/bind/xml"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	clientVersion "github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/common/promslog/flag"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
 	webflag "github.com/prometheus/exporter-toolkit/web/kingpinflag"
+	"github.com/prometheus/procfs"
 )
 
 const (
@@ -73,6 +88,11 @@ var (
 		[]string{"opcode"}, nil,
 	)
 	resolverCache = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, resolver, "cache_rrsets"),
+		"Number of RRSets in Cache database, by state: positive, negative, stale or ancient.",
+		[]string{"view", "type", "state"}, nil,
+	)
+	resolverCacheRaw = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, resolver, "cache_rrsets"),
 		"Number of RRSets in Cache database.",
 		[]string{"view", "type"}, nil,
@@ -133,6 +153,11 @@ var (
 			"Number of DNSSEC validation attempt errors.",
 			[]string{"view"}, nil,
 		),
+		"Prefetch": prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, resolver, "prefetch_total"),
+			"Number of prefetch queries sent for answers about to expire from the cache.",
+			[]string{"view"}, nil,
+		),
 	}
 	resolverLabelStats = map[string]*prometheus.Desc{
 		"QueryAbort":    resolverQueryErrors,
@@ -156,6 +181,11 @@ var (
 		"Number of responses sent.",
 		[]string{"result"}, nil,
 	)
+	serverStaleAnswers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "stale_answers_total"),
+		"Number of attempts to answer from stale cache data after a lookup failure (TryStale), of the successful ones (UsedStale) and of stale answers within stale-refresh-time (StaleRefresh).",
+		[]string{"result"}, nil,
+	)
 	serverLabelStats = map[string]*prometheus.Desc{
 		"QryDropped":  serverQueryErrors,
 		"QryFailure":  serverQueryErrors,
@@ -165,6 +195,10 @@ var (
 		"QrySERVFAIL": serverResponses,
 		"QryFORMERR":  serverResponses,
 		"QryNXDOMAIN": serverResponses,
+		// Reported since BIND 9.16.13 with stale-answer-enable.
+		"QryTryStale":     serverStaleAnswers,
+		"QryUsedStale":    serverStaleAnswers,
+		"QryStaleRefresh": serverStaleAnswers,
 	}
 	serverRcodes = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "", "response_rcodes_total"),
@@ -223,6 +257,46 @@ var (
 		"Zone serial number.",
 		[]string{"view", "zone_name"}, nil,
 	)
+	zoneDNSSECSignatures = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "zone", "dnssec_signatures_total"),
+		"Number of DNSSEC signatures generated for new (sign) and expiring (refresh) signatures.",
+		[]string{"view", "zone", "keytag", "operation"}, nil,
+	)
+	listenerInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "listener", "info"),
+		"Sockets of the socket manager in listener state, always 1.",
+		[]string{"address", "port", "transport"}, nil,
+	)
+	sockets = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "sockets"),
+		"Number of sockets of the socket manager by type and state. A socket is counted in each of its states.",
+		[]string{"type", "state"}, nil,
+	)
+	xfrinInProgress = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "xfrin", "in_progress"),
+		"Incoming zone transfers by state, 1 for each transfer in progress or waiting to start.",
+		[]string{"view", "zone", "state"}, nil,
+	)
+	xfrinDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "xfrin", "duration_seconds"),
+		"Time spent on the incoming zone transfer so far.",
+		[]string{"view", "zone"}, nil,
+	)
+	xfrinBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "xfrin", "bytes"),
+		"Number of bytes received by the incoming zone transfer so far.",
+		[]string{"view", "zone"}, nil,
+	)
+	xfrinMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "xfrin", "messages"),
+		"Number of messages received by the incoming zone transfer so far.",
+		[]string{"view", "zone"}, nil,
+	)
+	xfrinRecords = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "xfrin", "records"),
+		"Number of records received by the incoming zone transfer so far.",
+		[]string{"view", "zone"}, nil,
+	)
 )
 
 type collectorConstructor func(*slog.Logger, *bind.Statistics) prometheus.Collector
@@ -245,6 +319,7 @@ func (c *serverCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- incomingRequests
 	ch <- serverQueryErrors
 	ch <- serverResponses
+	ch <- serverStaleAnswers
 	ch <- serverRcodes
 	for _, desc := range serverMetricStats {
 		ch <- desc
@@ -253,9 +328,11 @@ func (c *serverCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements prometheus.Collector.
 func (c *serverCollector) Collect(ch chan<- prometheus.Metric) {
-	ch <- prometheus.MustNewConstMetric(
-		bootTime, prometheus.GaugeValue, float64(c.stats.Server.BootTime.Unix()),
-	)
+	if !c.stats.Server.BootTime.IsZero() {
+		ch <- prometheus.MustNewConstMetric(
+			bootTime, prometheus.GaugeValue, float64(c.stats.Server.BootTime.Unix()),
+		)
+	}
 	if !c.stats.Server.ConfigTime.IsZero() {
 		ch <- prometheus.MustNewConstMetric(
 			configTime, prometheus.GaugeValue, float64(c.stats.Server.ConfigTime.Unix()),
@@ -301,6 +378,9 @@ func (c *serverCollector) Collect(ch chan<- prometheus.Metric) {
 type viewCollector struct {
 	logger *slog.Logger
 	stats  *bind.Statistics
+	// rawCacheTypes exports the cache RRset types as reported by BIND,
+	// including the prefixes of their state.
+	rawCacheTypes bool
 }
 
 // newViewCollector implements collectorConstructor.
@@ -308,8 +388,19 @@ func newViewCollector(logger *slog.Logger, s *bind.Statistics) prometheus.Collec
 	return &viewCollector{logger: logger, stats: s}
 }
 
+// newRawCacheViewCollector implements collectorConstructor. It exports the
+// cache RRsets without the state label.
+func newRawCacheViewCollector(logger *slog.Logger, s *bind.Statistics) prometheus.Collector {
+	return &viewCollector{logger: logger, stats: s, rawCacheTypes: true}
+}
+
 // Describe implements prometheus.Collector.
 func (c *viewCollector) Describe(ch chan<- *prometheus.Desc) {
+	if c.rawCacheTypes {
+		ch <- resolverCacheRaw
+	} else {
+		ch <- resolverCache
+	}
 	ch <- resolverDNSSECSuccess
 	ch <- resolverQueries
 	ch <- resolverQueryDuration
@@ -323,10 +414,14 @@ func (c *viewCollector) Describe(ch chan<- *prometheus.Desc) {
 // Collect implements prometheus.Collector.
 func (c *viewCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, v := range c.stats.Views {
-		for _, s := range v.Cache {
-			ch <- prometheus.MustNewConstMetric(
-				resolverCache, prometheus.GaugeValue, float64(s.Gauge), v.Name, s.Name,
-			)
+		if c.rawCacheTypes {
+			for _, s := range v.Cache {
+				ch <- prometheus.MustNewConstMetric(
+					resolverCacheRaw, prometheus.GaugeValue, float64(s.Gauge), v.Name, s.Name,
+				)
+			}
+		} else {
+			c.collectCache(ch, v)
 		}
 		for _, s := range v.ResolverQueries {
 			ch <- prometheus.MustNewConstMetric(
@@ -353,12 +448,99 @@ func (c *viewCollector) Collect(ch chan<- prometheus.Metric) {
 			c.logger.Warn("Error parsing RTT", "err", err)
 		}
 	}
+}
 
+// Cache RRset states, in order of precedence.
+const (
+	cacheAncient  = "ancient"
+	cacheStale    = "stale"
+	cacheNegative = "negative"
+	cachePositive = "positive"
+)
+
+// cacheType splits a cache RRset type reported by BIND into the type and its
+// state. BIND prefixes the type with "~" for ancient, "#" for stale and "!"
+// for negative RRsets, possibly combined, e.g. "#!AAAA" for stale negative
+// answers. Combined prefixes are attributed to the state first in order of
+// precedence. The NXDOMAIN pseudo type counts negative names.
+func cacheType(name string) (string, string) {
+	var ancient, stale, negative bool
+	for len(name) > 1 && strings.IndexByte("~#!", name[0]) >= 0 {
+		switch name[0] {
+		case '~':
+			ancient = true
+		case '#':
+			stale = true
+		case '!':
+			negative = true
+		}
+		name = name[1:]
+	}
+	switch {
+	case ancient:
+		return name, cacheAncient
+	case stale:
+		return name, cacheStale
+	case negative, name == "NXDOMAIN":
+		return name, cacheNegative
+	}
+	return name, cachePositive
+}
+
+// collectCache exports the cache RRsets of v by type and state. RRsets with
+// combined prefixes are summed into their state.
+func (c *viewCollector) collectCache(ch chan<- prometheus.Metric, v bind.View) {
+	type key struct{ typ, state string }
+	var keys []key
+	sums := map[key]uint64{}
+	for _, s := range v.Cache {
+		typ, state := cacheType(s.Name)
+		k := key{typ, state}
+		if _, ok := sums[k]; !ok {
+			keys = append(keys, k)
+		}
+		sums[k] += s.Gauge
+	}
+	for _, k := range keys {
+		ch <- prometheus.MustNewConstMetric(
+			resolverCache, prometheus.GaugeValue, float64(sums[k]), v.Name, k.typ, k.state,
+		)
+	}
+}
+
+type zoneCollector struct {
+	logger *slog.Logger
+	stats  *bind.Statistics
+}
+
+// newZoneCollector implements collectorConstructor.
+func newZoneCollector(logger *slog.Logger, s *bind.Statistics) prometheus.Collector {
+	return &zoneCollector{logger: logger, stats: s}
+}
+
+// Describe implements prometheus.Collector.
+func (c *zoneCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- zoneSerial
+	ch <- zoneDNSSECSignatures
+}
+
+// Collect implements prometheus.Collector.
+func (c *zoneCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, v := range c.stats.ZoneViews {
 		for _, z := range v.ZoneData {
 			if suint, err := strconv.ParseUint(z.Serial, 10, 64); err == nil {
 				ch <- prometheus.MustNewConstMetric(
-					zoneSerial, prometheus.CounterValue, float64(suint), v.Name, z.Name,
+					zoneSerial, prometheus.GaugeValue, float64(suint), v.Name, z.Name,
+				)
+			}
+			for _, s := range z.DNSSECSign {
+				ch <- prometheus.MustNewConstMetric(
+					zoneDNSSECSignatures, prometheus.CounterValue, float64(s.Counter), v.Name, z.Name, s.Name, "sign",
+				)
+			}
+			for _, s := range z.DNSSECRefresh {
+				ch <- prometheus.MustNewConstMetric(
+					zoneDNSSECSignatures, prometheus.CounterValue, float64(s.Counter), v.Name, z.Name, s.Name, "refresh",
 				)
 			}
 		}
@@ -383,47 +565,223 @@ func (c *taskCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements prometheus.Collector.
 func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
-	threadModel := c.stats.TaskManager.ThreadModel
-	ch <- prometheus.MustNewConstMetric(
-		tasksRunning, prometheus.GaugeValue, float64(threadModel.TasksRunning),
-	)
-	ch <- prometheus.MustNewConstMetric(
-		workerThreads, prometheus.GaugeValue, float64(threadModel.WorkerThreads),
-	)
+	if tm := c.stats.TaskManager; tm != nil {
+		ch <- prometheus.MustNewConstMetric(
+			tasksRunning, prometheus.GaugeValue, float64(tm.ThreadModel.TasksRunning),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			workerThreads, prometheus.GaugeValue, float64(tm.ThreadModel.WorkerThreads),
+		)
+	} else if lm := c.stats.LoopManager; lm != nil {
+		ch <- prometheus.MustNewConstMetric(
+			workerThreads, prometheus.GaugeValue, float64(lm.Loops),
+		)
+	}
+}
+
+type transferCollector struct {
+	logger *slog.Logger
+	stats  *bind.Statistics
+}
+
+// newTransferCollector implements collectorConstructor.
+func newTransferCollector(logger *slog.Logger, s *bind.Statistics) prometheus.Collector {
+	return &transferCollector{logger: logger, stats: s}
+}
+
+// Describe implements prometheus.Collector.
+func (c *transferCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- xfrinInProgress
+	ch <- xfrinDuration
+	ch <- xfrinBytes
+	ch <- xfrinMessages
+	ch <- xfrinRecords
+}
+
+// Collect implements prometheus.Collector.
+func (c *transferCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, v := range c.stats.TransferViews {
+		for _, t := range v.Transfers {
+			ch <- prometheus.MustNewConstMetric(
+				xfrinInProgress, prometheus.GaugeValue, 1, v.Name, t.Zone, t.State,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				xfrinDuration, prometheus.GaugeValue, t.Duration.Seconds(), v.Name, t.Zone,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				xfrinBytes, prometheus.GaugeValue, float64(t.Bytes), v.Name, t.Zone,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				xfrinMessages, prometheus.GaugeValue, float64(t.Messages), v.Name, t.Zone,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				xfrinRecords, prometheus.GaugeValue, float64(t.Records), v.Name, t.Zone,
+			)
+		}
+	}
+}
+
+type listenerCollector struct {
+	logger *slog.Logger
+	stats  *bind.Statistics
+}
+
+// newListenerCollector implements collectorConstructor.
+func newListenerCollector(logger *slog.Logger, s *bind.Statistics) prometheus.Collector {
+	return &listenerCollector{logger: logger, stats: s}
+}
+
+// Describe implements prometheus.Collector.
+func (c *listenerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- listenerInfo
+	ch <- sockets
+}
+
+// Collect implements prometheus.Collector.
+func (c *listenerCollector) Collect(ch chan<- prometheus.Metric) {
+	type key struct{ typ, state string }
+	var keys []key
+	counts := map[key]int{}
+	listeners := map[[3]string]bool{}
+	for _, s := range c.stats.Sockets {
+		for _, state := range s.States {
+			k := key{s.Type, state}
+			if _, ok := counts[k]; !ok {
+				keys = append(keys, k)
+			}
+			counts[k]++
+
+			if state != "listener" {
+				continue
+			}
+			// Addresses are formatted as address#port by BIND.
+			addr, port := s.LocalAddress, ""
+			if i := strings.LastIndexByte(addr, '#'); i >= 0 {
+				addr, port = addr[:i], addr[i+1:]
+			}
+			l := [3]string{addr, port, s.Type}
+			if listeners[l] {
+				continue
+			}
+			listeners[l] = true
+			ch <- prometheus.MustNewConstMetric(
+				listenerInfo, prometheus.GaugeValue, 1, addr, port, s.Type,
+			)
+		}
+	}
+	for _, k := range keys {
+		ch <- prometheus.MustNewConstMetric(
+			sockets, prometheus.GaugeValue, float64(counts[k]), k.typ, k.state,
+		)
+	}
 }
 
 // Exporter collects Binds stats from the given server and exports them using
 // the prometheus metrics package.
 type Exporter struct {
-	client     bind.Client
-	collectors []collectorConstructor
-	groups     []bind.StatisticGroup
-	logger     *slog.Logger
+	client        bind.Client
+	collectors    []collectorConstructor
+	groups        []bind.StatisticGroup
+	filter        seriesFilter
+	seriesDropped *prometheus.CounterVec
+	logger        *slog.Logger
+
+	// config is named.conf. If set, the configured zones are compared with
+	// the loaded zones.
+	config *configFile
+	// lag, if set, compares the serials of secondary zones with their
+	// primaries configured in config.
+	lag *zoneLag
+	// unsupported records the groups the server was found not to provide,
+	// to only warn about them once.
+	unsupported *sync.Map
+	// version is the version of BIND reported by the last successful fetch.
+	version *atomic.Value
 }
 
 // NewExporter returns an initialized Exporter.
-func NewExporter(logger *slog.Logger, version, url string, timeout time.Duration, g []bind.StatisticGroup) *Exporter {
-	var c bind.Client
+func NewExporter(logger *slog.Logger, c bind.Client, g []bind.StatisticGroup, f seriesFilter) *Exporter {
+	return &Exporter{
+		logger:      logger,
+		client:      c,
+		collectors:  collectorsFor(g, f),
+		groups:      g,
+		filter:      f,
+		unsupported: &sync.Map{},
+		version:     &atomic.Value{},
+		seriesDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: exporter,
+			Name:      "series_dropped_total",
+			Help:      "Number of series dropped because a metric exceeded the maximum number of series.",
+		}, []string{"metric"}),
+	}
+}
+
+// newClient returns a client for the given statistics channel version. Responses
+// larger than maxSize bytes are rejected unless maxSize is zero. A file:// URL
+// reads saved statistics documents instead, detecting their format.
+func newClient(version, url string, timeout time.Duration, maxSize int64) (bind.Client, error) {
+	if u, err := neturl.Parse(url); err == nil && u.Scheme == "file" {
+		path, err := file.PathFromURL(u)
+		if err != nil {
+			return nil, err
+		}
+		return file.NewClient(path), nil
+	}
+
 	switch version {
 	case "xml", "xml.v3":
-		c = xml.NewClient(url, &http.Client{Timeout: timeout})
+		c := xml.NewClient(url, &http.Client{Timeout: timeout})
+		c.MaxResponseSize = maxSize
+		return c, nil
 	default:
-		c = json.NewClient(url, &http.Client{Timeout: timeout})
+		c := json.NewClient(url, &http.Client{Timeout: timeout})
+		c.MaxResponseSize = maxSize
+		return c, nil
 	}
+}
 
+// collectorsFor returns the collector constructors for the given statistic
+// groups.
+func collectorsFor(g []bind.StatisticGroup, f seriesFilter) []collectorConstructor {
 	var cs []collectorConstructor
 	for _, g := range g {
 		switch g {
 		case bind.ServerStats:
 			cs = append(cs, newServerCollector)
 		case bind.ViewStats:
-			cs = append(cs, newViewCollector)
+			if f.rawCacheTypes {
+				cs = append(cs, newRawCacheViewCollector)
+			} else {
+				cs = append(cs, newViewCollector)
+			}
+		case bind.ZoneStats:
+			cs = append(cs, newZoneCollector)
 		case bind.TaskStats:
 			cs = append(cs, newTaskCollector)
+		case bind.TransferStats:
+			cs = append(cs, newTransferCollector)
+		case bind.ListenerStats:
+			cs = append(cs, newListenerCollector)
 		}
 	}
+	return cs
+}
 
-	return &Exporter{logger: logger, client: c, collectors: cs, groups: g}
+// withGroups returns a copy of the Exporter which only fetches and exports the
+// given statistic groups.
+func (e *Exporter) withGroups(g []bind.StatisticGroup) *Exporter {
+	c := *e
+	c.collectors = collectorsFor(g, c.filter)
+	c.groups = g
+	return &c
+}
+
+// bindVersion returns the version of BIND reported by the last successful
+// fetch, if any.
+func (e *Exporter) bindVersion() string {
+	v, _ := e.version.Load().(string)
+	return v
 }
 
 // Describe describes all the metrics ever exported by the bind exporter. It
@@ -433,6 +791,13 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	for _, c := range e.collectors {
 		c(e.logger, &bind.Statistics{}).Describe(ch)
 	}
+	if e.config != nil && statisticGroups(e.groups).has(bind.ZoneStats) {
+		ch <- zoneConfiguredNotLoaded
+		if e.lag != nil {
+			e.lag.describe(ch)
+		}
+	}
+	e.seriesDropped.Describe(ch)
 }
 
 // Collect fetches the stats from configured bind location and delivers them as
@@ -440,16 +805,72 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	status := 0.
 	if stats, err := e.client.Stats(e.groups...); err == nil {
+		if stats.Version != "" {
+			e.version.Store(stats.Version)
+		}
+		for _, g := range stats.Unsupported {
+			if _, warned := e.unsupported.LoadOrStore(g, true); !warned {
+				e.logger.Warn("Statistic group not provided by BIND, no metrics are exported for it",
+					"group", g, "bind_version", stats.Version, "schema_version", stats.SchemaVersion)
+			}
+		}
+		e.filter.apply(&stats)
+		out, done := e.filter.limit(ch, e.seriesDropped)
 		for _, c := range e.collectors {
-			c(e.logger, &stats).Collect(ch)
+			c(e.logger, &stats).Collect(out)
+		}
+		if e.config != nil && statisticGroups(e.groups).has(bind.ZoneStats) {
+			e.collectConfiguredZones(out, &stats)
 		}
+		done()
 		status = 1
 	} else {
 		e.logger.Error("Couldn't retrieve BIND stats", "err", err)
 	}
+	e.seriesDropped.Collect(ch)
 	ch <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, status)
 }
 
+// handler serves the metrics of an Exporter. The statistic groups fetched
+// for a scrape can be restricted to a subset of the configured ones with one
+// or more collect[] URL parameters.
+type handler struct {
+	exporter *Exporter
+	logger   *slog.Logger
+}
+
+func newHandler(logger *slog.Logger, e *Exporter) *handler {
+	return &handler{exporter: e, logger: logger}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e := h.exporter
+	if filters := r.URL.Query()["collect[]"]; len(filters) > 0 {
+		var groups statisticGroups
+		if err := groups.Set(strings.Join(filters, ",")); err != nil {
+			h.logger.Warn("Invalid collect[] parameter", "err", err)
+			http.Error(w, fmt.Sprintf("Couldn't create filtered metrics handler: %s", err), http.StatusBadRequest)
+			return
+		}
+		for _, g := range groups {
+			if !statisticGroups(e.groups).has(g) {
+				h.logger.Warn("Invalid collect[] parameter", "group", g)
+				http.Error(w, fmt.Sprintf("Couldn't create filtered metrics handler: statistics group %q not enabled with --bind.stats-groups", g), http.StatusBadRequest)
+				return
+			}
+		}
+		e = e.withGroups(groups)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer, registry}
+	promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{
+		ErrorLog: slog.NewLogLogger(h.logger.Handler(), slog.LevelError),
+	}).ServeHTTP(w, r)
+}
+
 func histogram(stats []bind.Counter) (map[float64]uint64, uint64, error) {
 	buckets := map[float64]uint64{}
 	var count uint64
@@ -497,6 +918,15 @@ func (s *statisticGroups) String() string {
 	return strings.Join(groups, ",")
 }
 
+func (s statisticGroups) has(g bind.StatisticGroup) bool {
+	for _, existing := range s {
+		if existing == g {
+			return true
+		}
+	}
+	return false
+}
+
 // Set implements flag.Value.
 func (s *statisticGroups) Set(value string) error {
 	*s = []bind.StatisticGroup{}
@@ -510,8 +940,14 @@ func (s *statisticGroups) Set(value string) error {
 			sg = bind.ServerStats
 		case string(bind.ViewStats):
 			sg = bind.ViewStats
+		case string(bind.ZoneStats):
+			sg = bind.ZoneStats
 		case string(bind.TaskStats):
 			sg = bind.TaskStats
+		case string(bind.TransferStats):
+			sg = bind.TransferStats
+		case string(bind.ListenerStats):
+			sg = bind.ListenerStats
 		default:
 			return fmt.Errorf("unknown stats group %q", dt)
 		}
@@ -527,23 +963,175 @@ func (s *statisticGroups) Set(value string) error {
 
 func main() {
 	var (
-		bindURI = kingpin.Flag("bind.stats-url",
-			"HTTP XML API address of BIND server",
-		).Default("http://localhost:8053/").String()
+		bindURISet bool
+		bindURI    = kingpin.Flag("bind.stats-url",
+			"HTTP XML API address of BIND server, or file:// path of saved statistics (file:- for stdin). Defaults to the first statistics channel of --bind.config if set",
+		).Default("http://localhost:8053/").IsSetByUser(&bindURISet).String()
 		bindTimeout = kingpin.Flag("bind.timeout",
 			"Timeout for trying to get stats from BIND server",
 		).Default("10s").Duration()
+		bindMaxResponseSize = kingpin.Flag("bind.max-response-size",
+			"Maximum size of a statistics document returned by BIND, 0 for no limit",
+		).Default("0").Bytes()
+		bindStatsFile = kingpin.Flag("bind.stats-file",
+			"Path to BIND's statistics file to read instead of querying the statistics channel",
+		).Default("").String()
+		bindStatsFileCommand = kingpin.Flag("bind.stats-file.command",
+			"Command run to dump statistics before reading the statistics file, e.g. \"rndc stats\"",
+		).Default("").String()
+		rndcAddress = kingpin.Flag("bind.rndc-address",
+			"Address of BIND's control channel to export the rndc status from, e.g. "+rndc.DefaultAddress+" (default: disabled)",
+		).Default("").String()
+		rndcKeyFile = kingpin.Flag("bind.rndc-key-file",
+			"Path to the key used to authenticate to the control channel",
+		).Default("/etc/bind/rndc.key").String()
+		bindConfig = kingpin.Flag("bind.config",
+			"Path to named.conf to export the configured zones and, if the control channel isn't queried, the client limits from, e.g. "+namedconf.DefaultPath+" (default: disabled)",
+		).Default("").String()
+		queryLog = kingpin.Flag("bind.querylog",
+			"Path to named's query log to export query counts and the most active clients and names from (default: disabled)",
+		).Default("").String()
+		queryLogTopN = kingpin.Flag("bind.querylog.top-n",
+			"Number of most active clients and most queried names to export from the query log",
+		).Default("10").Int()
+		queryLogWindow = kingpin.Flag("bind.querylog.window",
+			"Window over which the most active clients and most queried names are determined",
+		).Default("1m").Duration()
+		dnstapSocket = kingpin.Flag("bind.dnstap-socket",
+			"Path of the unix socket to receive dnstap messages from named on (default: disabled)",
+		).Default("").String()
+		dnstapSocketMode = kingpin.Flag("bind.dnstap.socket-mode",
+			"Permissions of the dnstap socket in octal, e.g. 0660 (default: according to the umask)",
+		).Default("").String()
+		dnstapSocketGroup = kingpin.Flag("bind.dnstap.socket-group",
+			"Group name or ID of the dnstap socket, e.g. bind (default: the exporter's group)",
+		).Default("").String()
+		dnstapIPv4PrefixLength = kingpin.Flag("bind.dnstap.ipv4-prefix-length",
+			"Prefix length IPv4 client addresses are aggregated to",
+		).Default("24").Int()
+		dnstapIPv6PrefixLength = kingpin.Flag("bind.dnstap.ipv6-prefix-length",
+			"Prefix length IPv6 client addresses are aggregated to",
+		).Default("56").Int()
+		dnstapMaxSubnets = kingpin.Flag("bind.dnstap.max-client-subnets",
+			"Maximum number of client subnets exported, further subnets are counted as \"other\"",
+		).Default("1000").Int()
+		dnstapMaxServers = kingpin.Flag("bind.dnstap.max-upstream-servers",
+			"Maximum number of upstream servers exported, further servers are counted as \"other\"",
+		).Default("100").Int()
+		probeConfig = kingpin.Flag("bind.probe-config",
+			"Path to a YAML file of DNS queries to probe named with (default: disabled)",
+		).Default("").String()
+		probeTarget = kingpin.Flag("bind.probe-target",
+			"Address of named the probes are sent to",
+		).Default("127.0.0.1:53").String()
+		probeInterval = kingpin.Flag("bind.probe-interval",
+			"Interval at which the probes are run",
+		).Default("30s").Duration()
+		zoneLag = kingpin.Flag("bind.zone-lag",
+			"Query the primaries of secondary zones configured in --bind.config for their serial",
+		).Default("false").Bool()
+		zoneLagInterval = kingpin.Flag("bind.zone-lag.interval",
+			"Interval at which the primaries of secondary zones are queried",
+		).Default("1m").Duration()
+		dnssecEnabled = kingpin.Flag("bind.dnssec",
+			"Inspect the signatures and keys of the signed zones configured in --bind.config",
+		).Default("false").Bool()
+		dnssecSource = kingpin.Flag("bind.dnssec.source",
+			"Where to read signed zones from, \"axfr\" to transfer them from named or \"file\" to read text zone files",
+		).Default(dnssecSourceAXFR).Enum(dnssecSourceAXFR, dnssecSourceFile)
+		dnssecAXFRAddress = kingpin.Flag("bind.dnssec.axfr-address",
+			"Address of named signed zones are transferred from",
+		).Default("127.0.0.1:53").String()
+		dnssecInterval = kingpin.Flag("bind.dnssec.interval",
+			"Interval at which signed zones are inspected",
+		).Default("5m").Duration()
 		bindPidFile = kingpin.Flag("bind.pid-file",
 			"Path to BIND's pid file to export process information",
 		).Default("/run/named/named.pid").String()
+		bindChroot = kingpin.Flag("bind.chroot",
+			"Directory named is chrooted into with its -t option, which --bind.pid-file is relative to",
+		).Default("").String()
+		threadStats = kingpin.Flag("bind.thread-stats",
+			"Export the CPU time and states of named's threads, grouped by thread name",
+		).Default("false").Bool()
+		kernelStats = kingpin.Flag("bind.kernel-stats",
+			"Export the kernel's UDP and TCP drop counters of named's network namespace and listening sockets",
+		).Default("false").Bool()
+		procPath = kingpin.Flag("bind.proc-path",
+			"Mount point of the proc filesystem named's process information is read from",
+		).Default(procfs.DefaultMountPoint).String()
 		bindVersion = kingpin.Flag("bind.stats-version",
 			"BIND statistics channel",
 		).Default("json").Enum("json", "xml", "xml.v3", "auto")
+		viewInclude = kingpin.Flag("bind.view-include",
+			"Regexp of views to export (default: all)",
+		).Regexp()
+		viewExclude = kingpin.Flag("bind.view-exclude",
+			"Regexp of views to exclude from export",
+		).Regexp()
+		zoneInclude = kingpin.Flag("bind.zone-include",
+			"Regexp of zones to export (default: all)",
+		).Regexp()
+		zoneExclude = kingpin.Flag("bind.zone-exclude",
+			"Regexp of zones to exclude from export",
+		).Regexp()
+		dropInternalView = kingpin.Flag("bind.drop-internal-view",
+			"Don't export statistics of BIND's internal _bind view",
+		).Default("false").Bool()
+		zoneDNSSEC = kingpin.Flag("bind.zone-dnssec-signatures",
+			"Export the DNSSEC signing counters of zones per key, which multiplies the number of series per zone",
+		).Default("false").Bool()
+		rawCacheTypes = kingpin.Flag("bind.cache-rrsets.raw",
+			"Export cache RRset types with BIND's state prefixes (!, #, ~) in the type label and without the state label, as in earlier releases",
+		).Default("false").Bool()
+		maxSeries = kingpin.Flag("bind.max-series-per-metric",
+			"Maximum number of series exported per metric in a scrape, 0 for no limit",
+		).Default("0").Int()
+		pushURL = kingpin.Flag("push.url",
+			"URL of a Prometheus remote-write endpoint to push the metrics to (default: disabled)",
+		).Default("").String()
+		pushInterval = kingpin.Flag("push.interval",
+			"Interval at which the metrics are pushed",
+		).Default("30s").Duration()
+		pushTimeout = kingpin.Flag("push.timeout",
+			"Timeout of remote-write requests",
+		).Default("10s").Duration()
+		pushHTTPConfig = kingpin.Flag("push.http-config-file",
+			"Path to a YAML file of the HTTP client configuration of remote-write requests, e.g. for authentication and TLS",
+		).Default("").String()
+		pushBufferSize = kingpin.Flag("push.buffer-size",
+			"Maximum number of remote-write requests buffered while the endpoint can't be reached, the oldest are dropped beyond",
+		).Default("720").Int()
+		pushBufferDir = kingpin.Flag("push.buffer-dir",
+			"Directory remote-write requests are buffered in to survive restarts (default: in memory)",
+		).Default("").String()
+		otlpEndpoint = kingpin.Flag("otlp.endpoint",
+			"URL to export the metrics to via OTLP, e.g. http://localhost:4318/v1/metrics for HTTP or http://localhost:4317 for gRPC (default: disabled)",
+		).Default("").String()
+		otlpProtocol = kingpin.Flag("otlp.protocol",
+			"Transport of OTLP requests",
+		).Default(string(otlp.HTTP)).Enum(string(otlp.HTTP), string(otlp.GRPC))
+		otlpInterval = kingpin.Flag("otlp.interval",
+			"Interval at which the metrics are exported via OTLP",
+		).Default("60s").Duration()
+		otlpTimeout = kingpin.Flag("otlp.timeout",
+			"Timeout of OTLP requests",
+		).Default("10s").Duration()
+		otlpHTTPConfig = kingpin.Flag("otlp.http-config-file",
+			"Path to a YAML file of the HTTP client configuration of OTLP requests, e.g. for authentication and TLS",
+		).Default("").String()
+		otlpInstance = kingpin.Flag("otlp.instance",
+			"Value of the service.instance.id resource attribute (default: host name)",
+		).Default("").String()
 		metricsPath = kingpin.Flag(
 			"web.telemetry-path", "Path under which to expose metrics",
 		).Default("/metrics").String()
 
-		groups statisticGroups
+		groups       statisticGroups
+		processMatch processMatchers
+		procFS       procfs.FS
+		pushLabels   externalLabels
+		otlpResource resourceAttributes
 	)
 
 	toolkitFlags := webflag.AddFlags(kingpin.CommandLine, ":9119")
@@ -551,9 +1139,21 @@ func main() {
 	kingpin.Flag("bind.stats-groups",
 		"Comma-separated list of statistics to collect",
 	).Default((&statisticGroups{
-		bind.ServerStats, bind.ViewStats,
+		bind.ServerStats, bind.ViewStats, bind.ZoneStats,
 	}).String()).SetValue(&groups)
 
+	kingpin.Flag("bind.process",
+		"Find named's process by a regexp of its name or command line instead of --bind.pid-file, as [instance=]regexp; repeat for several named instances, whose process metrics are labeled by instance",
+	).SetValue(&processMatch)
+
+	kingpin.Flag("push.external-label",
+		"Label added to the pushed series as name=value, e.g. instance=resolver1; repeat for several labels",
+	).SetValue(&pushLabels)
+
+	kingpin.Flag("otlp.resource-attribute",
+		"Resource attribute of the exported metrics as key=value, overriding the default ones; repeat for several attributes",
+	).SetValue(&otlpResource)
+
 	promslogConfig := &promslog.Config{}
 	flag.AddFlags(kingpin.CommandLine, promslogConfig)
 	kingpin.Version(version.Print(exporter))
@@ -564,20 +1164,220 @@ func main() {
 	logger.Info("Starting bind_exporter", "version", version.Info())
 	logger.Info("Build context", "build_context", version.BuildContext())
 	logger.Info("Collectors enabled", "collectors", groups.String())
+	if groups.has(bind.ViewStats) && !groups.has(bind.ZoneStats) {
+		logger.Warn("Zone serials are no longer part of the view statistics group, add the zones group to --bind.stats-groups to keep exporting them")
+	}
 
-	prometheus.MustRegister(
-		clientVersion.NewCollector(exporter),
-		NewExporter(logger, *bindVersion, *bindURI, *bindTimeout, groups),
-	)
-	if *bindPidFile != "" {
-		procExporter := collectors.NewProcessCollector(collectors.ProcessCollectorOpts{
-			PidFn:     prometheus.NewPidFileFn(*bindPidFile),
+	prometheus.MustRegister(clientVersion.NewCollector(exporter))
+	if *rndcAddress != "" {
+		key, err := rndc.ReadKeyFile(*rndcKeyFile)
+		if err != nil {
+			logger.Error("Error reading rndc key", "err", err)
+			os.Exit(1)
+		}
+		prometheus.MustRegister(newRNDCCollector(logger, rndc.NewClient(*rndcAddress, key, *bindTimeout)))
+	}
+	if *queryLog != "" {
+		c := newQueryLogCollector(logger, *queryLogTopN, *queryLogWindow)
+		prometheus.MustRegister(c)
+		go c.tail(context.Background(), *queryLog, time.Second)
+	}
+	if *dnstapSocket != "" {
+		mode, gid, err := dnstapSocketOwnership(*dnstapSocketMode, *dnstapSocketGroup)
+		if err != nil {
+			logger.Error("Invalid dnstap socket option", "err", err)
+			os.Exit(1)
+		}
+		l, err := dnstap.Listen(*dnstapSocket, mode, gid)
+		if err != nil {
+			logger.Error("Error listening for dnstap messages", "err", err)
+			os.Exit(1)
+		}
+		c := newDNSTapCollector(logger, *dnstapIPv4PrefixLength, *dnstapIPv6PrefixLength, *dnstapMaxSubnets, *dnstapMaxServers)
+		prometheus.MustRegister(c)
+		go c.serve(l)
+	}
+	if *probeConfig != "" {
+		conf, err := probe.LoadConfig(*probeConfig)
+		if err != nil {
+			logger.Error("Error loading probe configuration", "err", err)
+			os.Exit(1)
+		}
+		c := newProbeCollector(logger, conf, *probeTarget)
+		prometheus.MustRegister(c)
+		go c.run(context.Background(), *probeInterval)
+	}
+	var processes []namedProcess
+	if len(processMatch) > 0 || *threadStats {
+		fs, err := procfs.NewFS(*procPath)
+		if err != nil {
+			logger.Error("Error opening the proc filesystem", "err", err)
+			os.Exit(1)
+		}
+		procFS = fs
+	}
+	if len(processMatch) > 0 {
+		for _, m := range processMatch {
+			processes = append(processes, namedProcess{instance: m.instance, pidFn: newProcessPidFn(procFS, m.re)})
+		}
+	} else if *bindPidFile != "" {
+		processes = append(processes, namedProcess{pidFn: prometheus.NewPidFileFn(filepath.Join(*bindChroot, *bindPidFile))})
+	}
+	if (*threadStats || *kernelStats) && len(processes) == 0 {
+		logger.Error("--bind.thread-stats and --bind.kernel-stats require --bind.pid-file or --bind.process")
+		os.Exit(1)
+	}
+	for _, p := range processes {
+		// Each discovered instance gets its own set of process metrics.
+		reg := prometheus.DefaultRegisterer
+		if p.instance != "" {
+			reg = prometheus.WrapRegistererWith(prometheus.Labels{instanceLabel: p.instance}, reg)
+		}
+		reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{
+			PidFn:     p.pidFn,
 			Namespace: namespace,
-		})
-		prometheus.MustRegister(procExporter)
+		}))
+		if *threadStats {
+			reg.MustRegister(newThreadCollector(logger, procFS, p.pidFn))
+		}
+		if *kernelStats {
+			reg.MustRegister(newKernelCollector(logger, *procPath, p.pidFn))
+		}
+	}
+	if len(processes) > 0 {
+		prometheus.MustRegister(newDiscoveryCollector(logger, processes))
+	}
+
+	filter := seriesFilter{
+		viewInclude:      *viewInclude,
+		viewExclude:      *viewExclude,
+		zoneInclude:      *zoneInclude,
+		zoneExclude:      *zoneExclude,
+		dropInternalView: *dropInternalView,
+		maxSeries:        *maxSeries,
+		zoneDNSSEC:       *zoneDNSSEC,
+		rawCacheTypes:    *rawCacheTypes,
+	}
+	if *bindConfig != "" && !bindURISet {
+		conf, err := namedconf.ParseFile(*bindConfig)
+		if err != nil {
+			logger.Error("Error parsing configuration file", "err", err)
+			os.Exit(1)
+		}
+		if addrs := conf.StatisticsChannels(); len(addrs) > 0 {
+			*bindURI = "http://" + addrs[0] + "/"
+			logger.Info("Using statistics channel from configuration file", "url", *bindURI)
+		}
+	}
+	client, err := newClient(*bindVersion, *bindURI, *bindTimeout, int64(*bindMaxResponseSize))
+	if err != nil {
+		logger.Error("Error creating the statistics client", "err", err)
+		os.Exit(1)
 	}
+	if *bindStatsFile != "" {
+		client = statsfile.NewClient(logger, *bindStatsFile, strings.Fields(*bindStatsFileCommand), *bindTimeout)
+	}
+	e := NewExporter(logger, client, groups, filter)
+	if *bindConfig != "" {
+		e.config = newConfigFile(*bindConfig)
+		prometheus.MustRegister(newConfigCollector(logger, e.config, *rndcAddress == "", filter))
+		if *zoneLag {
+			e.lag = newZoneLag(logger, e.config, *bindTimeout, filter)
+			go e.lag.run(context.Background(), *zoneLagInterval)
+		}
+		if *dnssecEnabled {
+			c := newDNSSECCollector(logger, *bindConfig, *dnssecSource, *dnssecAXFRAddress, *bindTimeout, filter)
+			if err := c.check(); err != nil {
+				logger.Error("Can't inspect DNSSEC signatures", "err", err)
+				os.Exit(1)
+			}
+			prometheus.MustRegister(c)
+			go c.run(context.Background(), *dnssecInterval)
+		}
+	} else if *zoneLag || *dnssecEnabled {
+		logger.Error("--bind.zone-lag and --bind.dnssec require --bind.config")
+		os.Exit(1)
+	}
+	if *pushURL != "" {
+		httpConfig := config.DefaultHTTPClientConfig
+		if *pushHTTPConfig != "" {
+			c, _, err := config.LoadHTTPConfigFile(*pushHTTPConfig)
+			if err != nil {
+				logger.Error("Error loading remote-write HTTP configuration", "err", err)
+				os.Exit(1)
+			}
+			httpConfig = *c
+		}
+		httpClient, err := config.NewClientFromConfig(httpConfig, exporter)
+		if err != nil {
+			logger.Error("Error creating remote-write HTTP client", "err", err)
+			os.Exit(1)
+		}
+		httpClient.Timeout = *pushTimeout
+		buffer, err := remotewrite.NewBuffer(*pushBufferDir, *pushBufferSize)
+		if err != nil {
+			logger.Error("Error opening remote-write buffer", "err", err)
+			os.Exit(1)
+		}
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(e)
+		rw := remotewrite.NewClient(*pushURL, httpClient, exporter+"/"+version.Version)
+		p := newPusher(logger, prometheus.Gatherers{prometheus.DefaultGatherer, registry}, rw, buffer, pushLabels)
+		prometheus.MustRegister(p)
+		go p.run(context.Background(), *pushInterval)
+	}
+	if *otlpEndpoint != "" {
+		var httpClient *http.Client
+		if otlp.Protocol(*otlpProtocol) == otlp.GRPC && strings.HasPrefix(*otlpEndpoint, "http://") {
+			// gRPC without TLS requires HTTP/2 with prior knowledge, which
+			// the configurable HTTP client doesn't support.
+			if *otlpHTTPConfig != "" {
+				logger.Error("--otlp.http-config-file isn't supported with gRPC without TLS")
+				os.Exit(1)
+			}
+			t := &http.Transport{Protocols: new(http.Protocols)}
+			t.Protocols.SetUnencryptedHTTP2(true)
+			httpClient = &http.Client{Transport: t}
+		} else {
+			httpConfig := config.DefaultHTTPClientConfig
+			if *otlpHTTPConfig != "" {
+				c, _, err := config.LoadHTTPConfigFile(*otlpHTTPConfig)
+				if err != nil {
+					logger.Error("Error loading OTLP HTTP configuration", "err", err)
+					os.Exit(1)
+				}
+				httpConfig = *c
+			}
+			c, err := config.NewClientFromConfig(httpConfig, exporter)
+			if err != nil {
+				logger.Error("Error creating OTLP HTTP client", "err", err)
+				os.Exit(1)
+			}
+			httpClient = c
+		}
+		httpClient.Timeout = *otlpTimeout
 
-	http.Handle(*metricsPath, promhttp.Handler())
+		hostname, err := os.Hostname()
+		if err != nil {
+			logger.Warn("Couldn't get the host name", "err", err)
+		}
+		instance := *otlpInstance
+		if instance == "" {
+			instance = hostname
+		}
+		resource := []otlp.Attribute{{Key: "service.instance.id", Value: instance}}
+		if hostname != "" {
+			resource = append(resource, otlp.Attribute{Key: "host.name", Value: hostname})
+		}
+		for _, a := range otlpResource {
+			resource = setAttribute(resource, a)
+		}
+		client := otlp.NewClient(*otlpEndpoint, otlp.Protocol(*otlpProtocol), httpClient, exporter+"/"+version.Version)
+		o := newOTLPExporter(logger, e, prometheus.DefaultGatherer, client, resource)
+		prometheus.MustRegister(o)
+		go o.run(context.Background(), *otlpInterval)
+	}
+	http.Handle(*metricsPath, newHandler(logger, e))
 	if *metricsPath != "/" && *metricsPath != "" {
 		landingConfig := web.LandingConfig{
 			Name:        "Bind Exporter",
@@ -15,15 +15,36 @@ package main
 
 import (
 	"bytes"
+	"compress/zlib"
+	"context"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/miekg/dns"
 	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/dnstap"
+	"github.com/prometheus-community/bind_exporter/bind/namedconf"
+	"github.com/prometheus-community/bind_exporter/bind/otlp"
+	"github.com/prometheus-community/bind_exporter/bind/probe"
+	"github.com/prometheus-community/bind_exporter/bind/remotewrite"
+	"github.com/prometheus-community/bind_exporter/bind/statsfile"
+	"github.com/prometheus-community/bind_exporter/internal/otlptest"
+	"github.com/prometheus-community/bind_exporter/internal/remotewritetest"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/procfs"
 )
 
 var (
@@ -33,6 +54,9 @@ var (
 		`bind_incoming_requests_total{opcode="QUERY"} 37634`,
 		`bind_responses_total{result="Success"} 29313`,
 		`bind_query_duplicates_total 216`,
+		`bind_stale_answers_total{result="TryStale"} 96`,
+		`bind_stale_answers_total{result="UsedStale"} 81`,
+		`bind_stale_answers_total{result="StaleRefresh"} 17`,
 		`bind_query_errors_total{error="Dropped"} 237`,
 		`bind_query_errors_total{error="Failure"} 2950`,
 		`bind_query_recursions_total 60946`,
@@ -45,7 +69,9 @@ var (
 		`bind_response_rcodes_total{rcode="NXDOMAIN"} 33958`,
 	}
 	viewStats = []string{
-		`bind_resolver_cache_rrsets{type="A",view="_default"} 34324`,
+		`bind_resolver_cache_rrsets{state="positive",type="A",view="_default"} 34324`,
+		`bind_resolver_cache_rrsets{state="negative",type="AAAA",view="_default"} 13`,
+		`bind_resolver_cache_rrsets{state="stale",type="A",view="_default"} 5`,
 		`bind_resolver_queries_total{type="CNAME",view="_default"} 28`,
 		`bind_resolver_response_errors_total{error="FORMERR",view="_bind"} 0`,
 		`bind_resolver_response_errors_total{error="FORMERR",view="_default"} 42906`,
@@ -56,16 +82,27 @@ var (
 		`bind_resolver_response_errors_total{error="SERVFAIL",view="_bind"} 0`,
 		`bind_resolver_response_errors_total{error="SERVFAIL",view="_default"} 7596`,
 		`bind_resolver_response_lame_total{view="_default"} 9108`,
+		`bind_resolver_prefetch_total{view="_default"} 512`,
 		`bind_resolver_query_duration_seconds_bucket{view="_default",le="0.01"} 38334`,
 		`bind_resolver_query_duration_seconds_bucket{view="_default",le="0.1"} 113122`,
 		`bind_resolver_query_duration_seconds_bucket{view="_default",le="0.5"} 182658`,
 		`bind_resolver_query_duration_seconds_bucket{view="_default",le="0.8"} 187375`,
 		`bind_resolver_query_duration_seconds_bucket{view="_default",le="1.6"} 188409`,
 		`bind_resolver_query_duration_seconds_bucket{view="_default",le="+Inf"} 227755`,
-		`bind_zone_serial{view="_default",zone_name="TEST_ZONE"} 123`,
 		`bind_resolver_response_errors_total{error="REFUSED",view="_bind"} 17`,
 		`bind_resolver_response_errors_total{error="REFUSED",view="_default"} 5798`,
 	}
+	zoneStats = []string{
+		`bind_zone_serial{view="_default",zone_name="TEST_ZONE"} 123`,
+	}
+	transferStats = []string{
+		`bind_xfrin_in_progress{state="Zone Transfer Request",view="_default",zone="TEST_ZONE"} 1`,
+		`bind_xfrin_duration_seconds{view="_default",zone="TEST_ZONE"} 12.5`,
+		`bind_xfrin_bytes{view="_default",zone="TEST_ZONE"} 56789`,
+		`bind_xfrin_messages{view="_default",zone="TEST_ZONE"} 34`,
+		`bind_xfrin_records{view="_default",zone="TEST_ZONE"} 1200`,
+		`bind_xfrin_in_progress{state="Pending",view="_default",zone="queued.example"} 1`,
+	}
 	taskStats = []string{
 		`bind_tasks_running 8`,
 		`bind_worker_threads 16`,
@@ -75,42 +112,879 @@ var (
 func TestBindExporterJSONClient(t *testing.T) {
 	bindExporterTest{
 		server:  newJSONServer(),
-		groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.TaskStats},
+		groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.ZoneStats, bind.TaskStats},
 		version: "json",
-		include: combine([]string{`bind_up 1`}, serverStats, viewStats, taskStats),
+		include: combine([]string{`bind_up 1`}, serverStats, viewStats, zoneStats, taskStats),
 	}.run(t)
 }
 
 func TestBindExporterV3Client(t *testing.T) {
 	bindExporterTest{
 		server:  newV3Server(),
-		groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.TaskStats},
+		groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.ZoneStats, bind.TaskStats},
+		version: "xml.v3",
+		include: combine([]string{`bind_up 1`}, serverStats, viewStats, zoneStats, taskStats),
+	}.run(t)
+}
+
+func TestBindExporterWithoutZones(t *testing.T) {
+	for version, server := range map[string]*httptest.Server{
+		"json":   newJSONServer(),
+		"xml.v3": newV3Server(),
+	} {
+		defer server.Close()
+		bindExporterTest{
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.RequestURI, "/zones") {
+					t.Errorf("%s: unexpected request for %s", version, r.RequestURI)
+				}
+				server.Config.Handler.ServeHTTP(w, r)
+			})),
+			groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats},
+			version: version,
+			include: combine([]string{`bind_up 1`}, serverStats, viewStats),
+			exclude: zoneStats,
+		}.run(t)
+	}
+}
+
+func TestBindExporterZoneDNSSEC(t *testing.T) {
+	signatures := []string{
+		`bind_zone_dnssec_signatures_total{keytag="12345",operation="sign",view="_default",zone="TEST_ZONE"} 42`,
+		`bind_zone_dnssec_signatures_total{keytag="12345",operation="refresh",view="_default",zone="TEST_ZONE"} 7`,
+	}
+	for _, tc := range []struct {
+		version string
+		server  *httptest.Server
+	}{
+		{"json", newJSONServer()},
+		{"xml.v3", newV3Server()},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			defer tc.server.Close()
+			groups := []bind.StatisticGroup{bind.ZoneStats}
+
+			// The counters are only exported on request.
+			bindExporterTest{
+				url:     tc.server.URL,
+				groups:  groups,
+				version: tc.version,
+				include: zoneStats,
+				exclude: []string{"bind_zone_dnssec_signatures_total"},
+			}.run(t)
+
+			bindExporterTest{
+				url:     tc.server.URL,
+				groups:  groups,
+				filter:  seriesFilter{zoneDNSSEC: true},
+				version: tc.version,
+				include: combine(zoneStats, signatures),
+			}.run(t)
+		})
+	}
+}
+
+func TestBindExporterTransfers(t *testing.T) {
+	for version, server := range map[string]*httptest.Server{
+		"json":   newJSONServerFor("fixtures/json/9.20"),
+		"xml.v3": newV3ServerFor("fixtures/xml/9.20"),
+	} {
+		defer server.Close()
+		bindExporterTest{
+			url:     server.URL,
+			groups:  []bind.StatisticGroup{bind.TransferStats},
+			version: version,
+			include: transferStats,
+			exclude: []string{`zone="version.bind"`},
+		}.run(t)
+
+		bindExporterTest{
+			url:     server.URL,
+			groups:  []bind.StatisticGroup{bind.TransferStats},
+			filter:  seriesFilter{zoneExclude: regexp.MustCompile(`^queued\.`)},
+			version: version,
+			include: transferStats[:5],
+			exclude: []string{`zone="queued.example"`},
+		}.run(t)
+	}
+}
+
+func TestBindExporterListeners(t *testing.T) {
+	for version, server := range map[string]*httptest.Server{
+		"json":   newJSONServerFor("fixtures/json/9.16"),
+		"xml.v3": newV3ServerFor("fixtures/xml/9.16"),
+	} {
+		bindExporterTest{
+			server:  server,
+			groups:  []bind.StatisticGroup{bind.ListenerStats},
+			version: version,
+			include: []string{
+				`bind_listener_info{address="192.0.2.53",port="53",transport="tcp"} 1`,
+				`bind_listener_info{address="2001:db8::53",port="53",transport="tcp"} 1`,
+				`bind_sockets{state="bound",type="tcp"} 3`,
+				`bind_sockets{state="bound",type="udp"} 2`,
+				`bind_sockets{state="connected",type="tcp"} 1`,
+				`bind_sockets{state="listener",type="tcp"} 2`,
+			},
+			exclude: []string{`transport="udp"`},
+		}.run(t)
+	}
+
+	// BIND 9.18 replaced the socket manager.
+	for version, server := range map[string]*httptest.Server{
+		"json":   newJSONServerFor("fixtures/json/9.20"),
+		"xml.v3": newV3ServerFor("fixtures/xml/9.20"),
+	} {
+		defer server.Close()
+		s, err := testClient(t, version, server.URL).Stats(bind.ListenerStats)
+		if err != nil {
+			t.Fatalf("%s: %s", version, err)
+		}
+		if want := []bind.StatisticGroup{bind.ListenerStats}; !reflect.DeepEqual(s.Unsupported, want) {
+			t.Errorf("%s: unsupported groups are %v, want %v", version, s.Unsupported, want)
+		}
+	}
+}
+
+func TestThreadCollector(t *testing.T) {
+	fs, err := procfs.NewFS("fixtures/proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newThreadCollector(promslog.NewNopLogger(), fs, func() (int, error) { return 1234, nil })
+	o, err := collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_process_thread_cpu_seconds_total{thread_group="isc-net"} 52`,
+		`bind_process_thread_cpu_seconds_total{thread_group="isc-timer"} 0.2`,
+		`bind_process_thread_cpu_seconds_total{thread_group="isc-worker"} 5`,
+		`bind_process_thread_cpu_seconds_total{thread_group="named"} 2`,
+		`bind_process_threads{state="running",thread_group="isc-net"} 1`,
+		`bind_process_threads{state="sleeping",thread_group="isc-net"} 1`,
+		`bind_process_threads{state="sleeping",thread_group="isc-worker"} 1`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+}
+
+func TestKernelCollector(t *testing.T) {
+	c := newKernelCollector(promslog.NewNopLogger(), "fixtures/proc", func() (int, error) { return 1234, nil })
+	o, err := collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_kernel_udp_rcvbuf_errors_total{family="ipv4"} 1300`,
+		`bind_kernel_udp_rcvbuf_errors_total{family="ipv6"} 40`,
+		`bind_kernel_udp_in_errors_total{family="ipv4"} 1337`,
+		`bind_kernel_udp_in_errors_total{family="ipv6"} 42`,
+		`bind_kernel_tcp_listen_overflows_total 17`,
+		`bind_kernel_tcp_listen_drops_total 19`,
+		`bind_kernel_listener_receive_queue{address="192.0.2.53",port="53",transport="tcp"} 0`,
+		`bind_kernel_listener_receive_queue{address="2001:db8::53",port="53",transport="tcp"} 3`,
+		`bind_kernel_listener_receive_queue{address="127.0.0.1",port="953",transport="tcp"} 0`,
+		`bind_kernel_listener_receive_queue{address="192.0.2.53",port="53",transport="udp"} 768`,
+		`bind_kernel_listener_receive_queue{address="2001:db8::53",port="53",transport="udp"} 0`,
+		`bind_kernel_listener_drops_total{address="192.0.2.53",port="53"} 7`,
+		`bind_kernel_listener_drops_total{address="2001:db8::53",port="53"} 1`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+	for _, m := range []string{
+		`port="22"`,
+		`port="41234"`,
+	} {
+		if bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to not find %q in output\n%s", m, o)
+		}
+	}
+}
+
+func TestProcessPidFn(t *testing.T) {
+	fs, err := procfs.NewFS("fixtures/proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for expr, want := range map[string]int{
+		`^named$`:                     1234,
+		`-t /var/lib/named`:           1234,
+		`-c /etc/bind/internal\.conf`: 2345,
+		`^/usr/sbin/sshd`:             9999,
+		`^unbound$`:                   0,
+	} {
+		pid, err := newProcessPidFn(fs, regexp.MustCompile(expr))()
+		if want == 0 {
+			if err == nil {
+				t.Errorf("%s: expected error, got pid %d", expr, pid)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", expr, err)
+		} else if pid != want {
+			t.Errorf("%s: got pid %d, want %d", expr, pid, want)
+		}
+	}
+}
+
+func TestProcessPidFnCache(t *testing.T) {
+	dir := t.TempDir()
+	proc := filepath.Join(dir, "2345")
+	if err := os.Mkdir(proc, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"cmdline", "comm", "stat"} {
+		b, err := os.ReadFile(filepath.Join("fixtures/proc/2345", f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(proc, f), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs, err := procfs.NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pidFn := newProcessPidFn(fs, regexp.MustCompile(`^named$`))
+	if pid, err := pidFn(); err != nil || pid != 2345 {
+		t.Fatalf("got pid %d, %v, want 2345", pid, err)
+	}
+	// The pid is reused by the other collectors of a scrape.
+	if err := os.RemoveAll(proc); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := pidFn(); err != nil || pid != 2345 {
+		t.Errorf("got pid %d, %v from cache, want 2345", pid, err)
+	}
+}
+
+func TestProcessMatchers(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		instance string
+		expr     string
+	}{
+		{value: `^named$`, instance: "named", expr: `^named$`},
+		{value: `external=-t /var/lib/named`, instance: "external", expr: `-t /var/lib/named`},
+		{value: `ns-1=^named$`, instance: "ns-1", expr: `^named$`},
+		{value: `-c=/etc/named.conf`, instance: "named", expr: `-c=/etc/named.conf`},
+		{value: `named .*-c=/etc/named.conf`, instance: "named", expr: `named .*-c=/etc/named.conf`},
+		{value: `internal=-c=/etc/internal.conf`, instance: "internal", expr: `-c=/etc/internal.conf`},
+	} {
+		var m processMatchers
+		if err := m.Set(tc.value); err != nil {
+			t.Errorf("%s: %v", tc.value, err)
+			continue
+		}
+		if m[0].instance != tc.instance || m[0].re.String() != tc.expr {
+			t.Errorf("%s: got instance %q and regexp %q, want %q and %q", tc.value, m[0].instance, m[0].re, tc.instance, tc.expr)
+		}
+	}
+}
+
+func TestDiscoveryCollector(t *testing.T) {
+	fs, err := procfs.NewFS("fixtures/proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m processMatchers
+	for _, v := range []string{`external=-t /var/lib/named`, `internal=internal\.conf`, `missing=unbound`} {
+		if err := m.Set(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Set(`internal=named`); err == nil {
+		t.Error("expected error for duplicated instance")
+	}
+	var processes []namedProcess
+	for _, p := range m {
+		processes = append(processes, namedProcess{instance: p.instance, pidFn: newProcessPidFn(fs, p.re)})
+	}
+	processes = append(processes, namedProcess{pidFn: prometheus.NewPidFileFn("fixtures/proc/named.pid")})
+
+	o, err := collect(newDiscoveryCollector(promslog.NewNopLogger(), processes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_exporter_process_discovered{named_instance="external"} 1`,
+		`bind_exporter_process_discovered{named_instance="internal"} 1`,
+		`bind_exporter_process_discovered{named_instance="missing"} 0`,
+		`bind_exporter_process_discovered{named_instance=""} 0`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+}
+
+func TestPusher(t *testing.T) {
+	bindServer := newJSONServer()
+	defer bindServer.Close()
+	e := NewExporter(promslog.NewNopLogger(), testClient(t, "json", bindServer.URL), []bind.StatisticGroup{bind.ServerStats}, seriesFilter{})
+	r := prometheus.NewRegistry()
+	r.MustRegister(e)
+
+	var (
+		up       bool
+		received [][]remotewrite.TimeSeries
+	)
+	receiver := remotewritetest.Receiver(func(s []remotewrite.TimeSeries) { received = append(received, s) })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !up {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		receiver.ServeHTTP(w, req)
+	}))
+	defer server.Close()
+
+	buffer, err := remotewrite.NewBuffer("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newPusher(promslog.NewNopLogger(), r, remotewrite.NewClient(server.URL, server.Client(), exporter), buffer,
+		[]remotewrite.Label{{Name: "instance", Value: "resolver1"}})
+
+	// Requests are buffered while the endpoint is unavailable.
+	p.gather()
+	if err := p.send(context.Background()); err == nil {
+		t.Fatal("expected error while the endpoint is unavailable")
+	}
+	p.gather()
+	if n := buffer.Len(); n != 2 {
+		t.Fatalf("got %d buffered requests, want 2", n)
+	}
+
+	up = true
+	if err := p.send(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := buffer.Len(); n != 0 {
+		t.Errorf("got %d buffered requests after sending, want 0", n)
+	}
+	if len(received) != 2 {
+		t.Fatalf("received %d requests, want 2", len(received))
+	}
+	want := []remotewrite.Label{
+		{Name: "__name__", Value: "bind_incoming_queries_total"},
+		{Name: "instance", Value: "resolver1"},
+		{Name: "type", Value: "A"},
+	}
+	found := false
+	for _, s := range received[0] {
+		if reflect.DeepEqual(s.Labels, want) {
+			found = true
+			if len(s.Samples) != 1 || s.Samples[0].Value != 128417 {
+				t.Errorf("got samples %v for %v, want 128417", s.Samples, want)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected to find series %v in %v", want, received[0])
+	}
+
+	o, err := collect(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_exporter_remote_write_requests_total{result="retry"} 1`,
+		`bind_exporter_remote_write_requests_total{result="success"} 2`,
+		`bind_exporter_remote_write_buffered_requests 0`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	bindServer := newJSONServer()
+	defer bindServer.Close()
+	e := NewExporter(promslog.NewNopLogger(), testClient(t, "json", bindServer.URL), []bind.StatisticGroup{bind.ServerStats, bind.ViewStats}, seriesFilter{})
+	other := prometheus.NewRegistry()
+	requests := prometheus.NewCounter(prometheus.CounterOpts{Name: "bind_exporter_test_requests_total", Help: "Test counter."})
+	requests.Add(3)
+	other.MustRegister(requests)
+
+	e.seriesDropped.WithLabelValues("bind_incoming_queries_total").Add(2)
+
+	var received []otlp.Request
+	server := httptest.NewServer(otlptest.Receiver(func(r otlp.Request) { received = append(received, r) }))
+	defer server.Close()
+
+	o := newOTLPExporter(promslog.NewNopLogger(), e, other, otlp.NewClient(server.URL+"/v1/metrics", otlp.HTTP, server.Client(), exporter),
+		[]otlp.Attribute{{Key: "service.instance.id", Value: "resolver1"}})
+	if err := o.export(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("received %d requests, want 1", len(received))
+	}
+	r := received[0]
+
+	wantResource := []otlp.Attribute{
+		{Key: "service.name", Value: "bind"},
+		{Key: "service.version", Value: "9.18.12-1-Debian"},
+		{Key: "service.instance.id", Value: "resolver1"},
+	}
+	if !reflect.DeepEqual(r.Resource, wantResource) {
+		t.Errorf("got resource %v, want %v", r.Resource, wantResource)
+	}
+
+	metrics := map[string]otlp.Metric{}
+	for _, m := range r.Metrics {
+		metrics[m.Name] = m
+	}
+	boot := time.Unix(1626325868, 0)
+
+	queries, ok := metrics["bind_incoming_queries"]
+	if !ok || queries.Type != otlp.Sum || !queries.Monotonic {
+		t.Fatalf("expected bind_incoming_queries as monotonic sum, got %+v", queries)
+	}
+	found := false
+	for _, p := range queries.Points {
+		if reflect.DeepEqual(p.Attributes, []otlp.Attribute{{Key: "type", Value: "A"}}) {
+			found = true
+			if p.Value != 128417 || !p.Start.Equal(boot) {
+				t.Errorf("got bind_incoming_queries{type=A} %v starting at %v, want 128417 starting at %v", p.Value, p.Start, boot)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected to find bind_incoming_queries{type=A} in %+v", queries.Points)
+	}
+
+	duration := metrics["bind_resolver_query_duration_seconds"]
+	if duration.Type != otlp.Histogram {
+		t.Fatalf("expected bind_resolver_query_duration_seconds as histogram, got %+v", duration)
+	}
+	for _, p := range duration.Points {
+		if !reflect.DeepEqual(p.Attributes, []otlp.Attribute{{Key: "view", Value: "_default"}}) {
+			continue
+		}
+		wantBounds := []float64{0.01, 0.1, 0.5, 0.8, 1.6}
+		wantCounts := []uint64{38334, 74788, 69536, 4717, 1034, 39346}
+		if p.Count != 227755 || !reflect.DeepEqual(p.Bounds, wantBounds) || !reflect.DeepEqual(p.BucketCounts, wantCounts) {
+			t.Errorf("got histogram count %d, bounds %v, counts %v, want 227755, %v, %v", p.Count, p.Bounds, p.BucketCounts, wantBounds, wantCounts)
+		}
+		if !p.Start.Equal(boot) {
+			t.Errorf("got histogram start %v, want %v", p.Start, boot)
+		}
+	}
+
+	if up := metrics["bind_up"]; up.Type != otlp.Gauge || len(up.Points) != 1 || up.Points[0].Value != 1 {
+		t.Errorf("expected bind_up gauge of 1, got %+v", up)
+	}
+	test := metrics["bind_exporter_test_requests"]
+	if len(test.Points) != 1 || test.Points[0].Value != 3 || !test.Points[0].Start.IsZero() {
+		t.Errorf("expected bind_exporter_test_requests of 3 without start time, got %+v", test)
+	}
+	dropped := metrics["bind_exporter_series_dropped"]
+	if len(dropped.Points) != 1 || dropped.Points[0].Value != 2 || !dropped.Points[0].Start.IsZero() {
+		t.Errorf("expected bind_exporter_series_dropped of 2 without start time, got %+v", dropped)
+	}
+}
+
+func TestBindExporterVersions(t *testing.T) {
+	groups := []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.ZoneStats, bind.TaskStats}
+	for _, tc := range []struct {
+		bind    string
+		include []string
+		exclude []string
+	}{
+		{
+			bind: "9.16",
+			include: []string{
+				`bind_incoming_queries_total{type="A"} 61877`,
+				`bind_resolver_cache_rrsets{state="positive",type="A",view="_default"} 5120`,
+				`bind_zone_serial{view="_default",zone_name="TEST_ZONE"} 2.023112001e+09`,
+				`bind_tasks_running 2`,
+				`bind_worker_threads 4`,
+			},
+			exclude: []string{"bind_resolver_prefetch_total", "bind_stale_answers_total"},
+		},
+		{
+			bind:    "9.18",
+			include: combine(serverStats, viewStats, zoneStats, taskStats),
+		},
+		{
+			// BIND 9.20 replaced the task manager with event loops.
+			bind: "9.20",
+			include: []string{
+				`bind_incoming_queries_total{type="A"} 61877`,
+				`bind_resolver_cache_rrsets{state="positive",type="A",view="_default"} 5120`,
+				`bind_zone_serial{view="_default",zone_name="TEST_ZONE"} 2.024090201e+09`,
+				`bind_worker_threads 4`,
+				`bind_resolver_prefetch_total{view="_default"} 201`,
+				`bind_stale_answers_total{result="TryStale"} 12`,
+				`bind_stale_answers_total{result="UsedStale"} 9`,
+				`bind_stale_answers_total{result="StaleRefresh"} 3`,
+			},
+			exclude: []string{"bind_tasks_running", "bind_prefetch_total"},
+		},
+	} {
+		for _, format := range []string{"json", "xml"} {
+			t.Run(tc.bind+"/"+format, func(t *testing.T) {
+				dir := "fixtures/" + format
+				if tc.bind != "9.18" {
+					dir += "/" + tc.bind
+				}
+				b := bindExporterTest{
+					groups:  groups,
+					include: combine([]string{`bind_up 1`}, tc.include),
+					exclude: tc.exclude,
+				}
+				if format == "json" {
+					b.server, b.version = newJSONServerFor(dir), "json"
+				} else {
+					b.server, b.version = newV3ServerFor(dir), "xml.v3"
+				}
+				b.run(t)
+			})
+		}
+	}
+}
+
+func TestBindExporterUnsupportedGroups(t *testing.T) {
+	for version, server := range map[string]*httptest.Server{
+		"json":   newJSONServer(),
+		"xml.v3": newV3Server(),
+	} {
+		defer server.Close()
+
+		c := testClient(t, version, server.URL)
+		s, err := c.Stats(bind.ServerStats, bind.TransferStats)
+		if err != nil {
+			t.Fatalf("%s: %s", version, err)
+		}
+		if want := []bind.StatisticGroup{bind.TransferStats}; !reflect.DeepEqual(s.Unsupported, want) {
+			t.Errorf("%s: unsupported groups are %v, want %v", version, s.Unsupported, want)
+		}
+		if s.Version == "" || s.SchemaVersion == "" {
+			t.Errorf("%s: version %q and schema version %q not detected", version, s.Version, s.SchemaVersion)
+		}
+
+		// Missing groups don't fail the scrape and are only logged once.
+		var logs bytes.Buffer
+		e := NewExporter(slog.New(slog.NewTextHandler(&logs, nil)), c, []bind.StatisticGroup{bind.ServerStats, bind.TransferStats}, seriesFilter{})
+		for i := 0; i < 2; i++ {
+			o, err := collect(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Contains(o, []byte(`bind_up 1`)) {
+				t.Errorf("%s: expected a successful scrape\n%s", version, o)
+			}
+		}
+		if n := strings.Count(logs.String(), "group=transfers"); n != 1 {
+			t.Errorf("%s: logged the unsupported group %d times, want once\n%s", version, n, logs.String())
+		}
+	}
+}
+
+func TestBindExporterRawCacheTypes(t *testing.T) {
+	bindExporterTest{
+		server:  newV3Server(),
+		groups:  []bind.StatisticGroup{bind.ViewStats},
+		filter:  seriesFilter{rawCacheTypes: true},
+		version: "xml.v3",
+		include: []string{
+			`bind_resolver_cache_rrsets{type="A",view="_default"} 34324`,
+			`bind_resolver_cache_rrsets{type="!AAAA",view="_default"} 13`,
+			`bind_resolver_cache_rrsets{type="#A",view="_default"} 5`,
+		},
+		exclude: []string{`state="`},
+	}.run(t)
+}
+
+func TestCacheType(t *testing.T) {
+	for name, want := range map[string][2]string{
+		"A":        {"A", "positive"},
+		"!AAAA":    {"AAAA", "negative"},
+		"#A":       {"A", "stale"},
+		"~MX":      {"MX", "ancient"},
+		"#!AAAA":   {"AAAA", "stale"},
+		"~#!NS":    {"NS", "ancient"},
+		"NXDOMAIN": {"NXDOMAIN", "negative"},
+		"!":        {"!", "positive"},
+	} {
+		typ, state := cacheType(name)
+		if typ != want[0] || state != want[1] {
+			t.Errorf("cacheType(%q) = %q, %q, want %q, %q", name, typ, state, want[0], want[1])
+		}
+	}
+}
+
+func TestBindExporterCompression(t *testing.T) {
+	for version, server := range map[string]*httptest.Server{
+		"json":   newJSONServer(),
+		"xml.v3": newV3Server(),
+	} {
+		defer server.Close()
+		bindExporterTest{
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.Contains(r.Header.Get("Accept-Encoding"), "deflate") {
+					t.Errorf("%s: expected deflate to be accepted for %s", version, r.RequestURI)
+				}
+				rr := httptest.NewRecorder()
+				server.Config.Handler.ServeHTTP(rr, r)
+				w.Header().Set("Content-Encoding", "deflate")
+				w.WriteHeader(rr.Code)
+				zw := zlib.NewWriter(w)
+				zw.Write(rr.Body.Bytes())
+				zw.Close()
+			})),
+			groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.ZoneStats, bind.TaskStats},
+			version: version,
+			include: combine([]string{`bind_up 1`}, serverStats, viewStats, zoneStats, taskStats),
+		}.run(t)
+	}
+}
+
+func TestBindExporterMaxResponseSize(t *testing.T) {
+	bindExporterTest{
+		server:  newV3Server(),
+		groups:  []bind.StatisticGroup{bind.ServerStats},
 		version: "xml.v3",
-		include: combine([]string{`bind_up 1`}, serverStats, viewStats, taskStats),
+		maxSize: 40000,
+		include: []string{`bind_up 1`},
+	}.run(t)
+
+	bindExporterTest{
+		server:  newV3Server(),
+		groups:  []bind.StatisticGroup{bind.ServerStats, bind.TaskStats},
+		version: "xml.v3",
+		maxSize: 40000,
+		include: []string{`bind_up 0`},
+		exclude: serverStats,
 	}.run(t)
 }
 
 func TestBindExporterBindFailure(t *testing.T) {
 	bindExporterTest{
 		server:  httptest.NewServer(http.HandlerFunc(http.NotFound)),
+		groups:  []bind.StatisticGroup{bind.ServerStats},
 		version: "xml.v3",
 		include: []string{`bind_up 0`},
 		exclude: serverStats,
 	}.run(t)
 }
 
+func TestBindExporterStatsFileClient(t *testing.T) {
+	var include []string
+	for _, m := range combine(serverStats, viewStats) {
+		// The statistics file doesn't contain timestamps and omits zero
+		// counters. The fixture predates the per-view prefetch and stale
+		// refresh counters.
+		if strings.HasPrefix(m, "bind_boot_time_seconds") || strings.HasPrefix(m, "bind_config_time_seconds") || strings.HasSuffix(m, "} 0") ||
+			strings.HasPrefix(m, "bind_resolver_prefetch_total") || strings.Contains(m, "StaleRefresh") {
+			continue
+		}
+		include = append(include, m)
+	}
+
+	bindExporterTest{
+		client:  statsfile.NewClient(promslog.NewNopLogger(), "fixtures/statsfile/named.stats", nil, time.Second),
+		groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats},
+		include: combine([]string{`bind_up 1`}, include),
+		exclude: []string{`bind_boot_time_seconds`, `bind_incoming_requests_total{opcode="QUERY"} 99999`},
+	}.run(t)
+}
+
+func TestBindExporterCollectParam(t *testing.T) {
+	server := newJSONServer()
+	defer server.Close()
+
+	c := testClient(t, "json", server.URL)
+	e := NewExporter(promslog.NewNopLogger(), c, []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.TaskStats}, seriesFilter{})
+	h := newHandler(promslog.NewNopLogger(), e)
+
+	for _, tc := range []struct {
+		query   string
+		status  int
+		include []string
+		exclude []string
+	}{
+		{
+			query:   "",
+			status:  http.StatusOK,
+			include: combine(serverStats, viewStats, taskStats),
+		},
+		{
+			query:   "?collect[]=server&collect[]=tasks",
+			status:  http.StatusOK,
+			include: combine(serverStats, taskStats),
+			exclude: viewStats,
+		},
+		{
+			query:  "?collect[]=zones",
+			status: http.StatusBadRequest,
+		},
+		{
+			query:  "?collect[]=foo",
+			status: http.StatusBadRequest,
+		},
+		{
+			query:  "?collect[]=server&collect[]=server",
+			status: http.StatusBadRequest,
+		},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics"+tc.query, nil))
+		if rr.Code != tc.status {
+			t.Errorf("%q: expected status %d, got %d", tc.query, tc.status, rr.Code)
+			continue
+		}
+		o := rr.Body.Bytes()
+		for _, m := range tc.include {
+			if !bytes.Contains(o, []byte(m)) {
+				t.Errorf("%q: expected to find metric %q in output\n%s", tc.query, m, o)
+			}
+		}
+		for _, m := range tc.exclude {
+			if bytes.Contains(o, []byte(m)) {
+				t.Errorf("%q: expected to not find metric %q in output\n%s", tc.query, m, o)
+			}
+		}
+	}
+}
+
+func TestBindExporterSeriesFilter(t *testing.T) {
+	groups := []bind.StatisticGroup{bind.ServerStats, bind.ViewStats}
+
+	bindExporterTest{
+		server:  newJSONServer(),
+		groups:  groups,
+		version: "json",
+		filter:  seriesFilter{dropInternalView: true},
+		include: []string{`bind_resolver_response_errors_total{error="FORMERR",view="_default"} 42906`},
+		exclude: []string{`view="_bind"`},
+	}.run(t)
+
+	bindExporterTest{
+		server:  newJSONServer(),
+		groups:  groups,
+		version: "json",
+		filter:  seriesFilter{viewExclude: regexp.MustCompile("^_default$"), zoneInclude: regexp.MustCompile("^OTHER_ZONE$")},
+		include: []string{`bind_resolver_response_errors_total{error="REFUSED",view="_bind"} 17`},
+		exclude: []string{`view="_default"`, `zone_name="TEST_ZONE"`},
+	}.run(t)
+
+	bindExporterTest{
+		server:  newJSONServer(),
+		groups:  groups,
+		version: "json",
+		filter:  seriesFilter{maxSeries: 2},
+		include: []string{
+			`bind_response_rcodes_total{rcode="NOERROR"} 989812`,
+			`bind_exporter_series_dropped_total{metric="bind_resolver_response_errors_total"} 8`,
+		},
+		exclude: []string{`bind_exporter_series_dropped_total{metric="bind_query_errors_total"}`},
+	}.run(t)
+}
+
+func TestBindExporterFileClient(t *testing.T) {
+	root := t.TempDir()
+	for p, f := range map[string]string{
+		"json/v1/server": "fixtures/json/server.json",
+		"json/v1/tasks":  "fixtures/json/tasks.json",
+		"json/v1/zones":  "fixtures/json/zones.json",
+	} {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(p)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, p), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bindExporterTest{
+		url:     "file://" + root,
+		groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.ZoneStats, bind.TaskStats},
+		include: combine([]string{`bind_up 1`}, serverStats, viewStats, zoneStats, taskStats),
+	}.run(t)
+
+	// A single document is used for all groups, regardless of the configured
+	// statistics version.
+	bindExporterTest{
+		url:     "file:fixtures/xml/server.xml",
+		groups:  []bind.StatisticGroup{bind.ServerStats, bind.ViewStats},
+		version: "json",
+		include: combine([]string{`bind_up 1`}, serverStats, viewStats),
+	}.run(t)
+
+	bindExporterTest{
+		url:     "file://" + filepath.Join(root, "missing"),
+		groups:  []bind.StatisticGroup{bind.ServerStats},
+		include: []string{`bind_up 0`},
+	}.run(t)
+
+	bindExporterTest{
+		url:     "file://localhost" + root,
+		groups:  []bind.StatisticGroup{bind.ServerStats},
+		include: combine([]string{`bind_up 1`}, serverStats),
+	}.run(t)
+
+	// Documents can't be read from other hosts.
+	if _, err := newClient("json", "file://ns1.example.com"+root, time.Second, 0); err == nil {
+		t.Error("expected error for file URL with a host")
+	}
+}
+
+// testClient returns a client for the statistics channel at url.
+func testClient(t *testing.T, version, url string) bind.Client {
+	t.Helper()
+	c, err := newClient(version, url, time.Second, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
 type bindExporterTest struct {
 	server  *httptest.Server
+	url     string
+	client  bind.Client
 	groups  []bind.StatisticGroup
+	filter  seriesFilter
 	version string
+	maxSize int64
+	config  string
 	include []string
 	exclude []string
 }
 
 func (b bindExporterTest) run(t *testing.T) {
-	defer b.server.Close()
+	if b.server != nil {
+		defer b.server.Close()
+		b.url = b.server.URL
+	}
 
-	o, err := collect(NewExporter(promslog.NewNopLogger(), b.version, b.server.URL, time.Second, b.groups))
+	c := b.client
+	if c == nil {
+		var err error
+		if c, err = newClient(b.version, b.url, time.Second, b.maxSize); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := NewExporter(promslog.NewNopLogger(), c, b.groups, b.filter)
+	if b.config != "" {
+		e.config = newConfigFile(b.config)
+	}
+	o, err := collect(e)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -154,13 +1028,41 @@ func collect(c prometheus.Collector) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// newV3Server serves the XML fixtures of BIND 9.18.
 func newV3Server() *httptest.Server {
-	m := map[string]string{
-		"/xml/v3/server": "fixtures/xml/server.xml",
-		"/xml/v3/status": "fixtures/xml/status.xml",
-		"/xml/v3/tasks":  "fixtures/xml/tasks.xml",
-		"/xml/v3/zones":  "fixtures/xml/zones.xml",
-	}
+	return newV3ServerFor("fixtures/xml")
+}
+
+// newV3ServerFor serves the XML fixtures in dir. Documents without a fixture
+// aren't found, like on versions of BIND not providing them.
+func newV3ServerFor(dir string) *httptest.Server {
+	return newFixtureServer(map[string]string{
+		"/xml/v3/server": dir + "/server.xml",
+		"/xml/v3/status": dir + "/status.xml",
+		"/xml/v3/tasks":  dir + "/tasks.xml",
+		"/xml/v3/zones":  dir + "/zones.xml",
+		"/xml/v3/xfrins": dir + "/xfrins.xml",
+		"/xml/v3/net":    dir + "/net.xml",
+	})
+}
+
+// newJSONServer serves the JSON fixtures of BIND 9.18.
+func newJSONServer() *httptest.Server {
+	return newJSONServerFor("fixtures/json")
+}
+
+// newJSONServerFor serves the JSON fixtures in dir.
+func newJSONServerFor(dir string) *httptest.Server {
+	return newFixtureServer(map[string]string{
+		"/json/v1/server": dir + "/server.json",
+		"/json/v1/tasks":  dir + "/tasks.json",
+		"/json/v1/zones":  dir + "/zones.json",
+		"/json/v1/xfrins": dir + "/xfrins.json",
+		"/json/v1/net":    dir + "/net.json",
+	})
+}
+
+func newFixtureServer(m map[string]string) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if f, ok := m[r.RequestURI]; ok {
 			http.ServeFile(w, r, f)
@@ -170,17 +1072,601 @@ func newV3Server() *httptest.Server {
 	}))
 }
 
-func newJSONServer() *httptest.Server {
-	m := map[string]string{
-		"/json/v1/server": "fixtures/json/server.json",
-		"/json/v1/tasks":  "fixtures/json/tasks.json",
-		"/json/v1/zones":  "fixtures/json/zones.json",
+func TestBindExporterZonesNotLoaded(t *testing.T) {
+	for version, server := range map[string]*httptest.Server{"json": newJSONServer(), "xml": newV3Server()} {
+		bindExporterTest{
+			server:  server,
+			groups:  []bind.StatisticGroup{bind.ZoneStats},
+			version: version,
+			config:  "fixtures/namedconf/named.conf",
+			include: []string{
+				`bind_up 1`,
+				`bind_zone_configured_not_loaded{view="_default",zone="TEST_ZONE"} 0`,
+				// Missing from the zone statistics.
+				`bind_zone_configured_not_loaded{view="_default",zone="broken.example"} 1`,
+				// Reported without serial after a syntax error.
+				`bind_zone_configured_not_loaded{view="_default",zone="syntax.example"} 1`,
+			},
+			exclude: []string{`zone="forward.example"`, `bind_zone_serial{view="_default",zone_name="syntax.example"}`},
+		}.run(t)
 	}
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if f, ok := m[r.RequestURI]; ok {
-			http.ServeFile(w, r, f)
-		} else {
-			http.NotFound(w, r)
+
+	// Without zone statistics there is nothing to compare with.
+	bindExporterTest{
+		server:  newJSONServer(),
+		groups:  []bind.StatisticGroup{bind.ServerStats},
+		version: "json",
+		config:  "fixtures/namedconf/named.conf",
+		exclude: []string{`bind_zone_configured_not_loaded`},
+	}.run(t)
+}
+
+func TestConfigCollector(t *testing.T) {
+	o, err := collect(newConfigCollector(promslog.NewNopLogger(), newConfigFile("fixtures/namedconf/named.conf"), true, seriesFilter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_config_up 1`,
+		`bind_recursive_clients_limit{kind="hard"} 5000`,
+		`bind_tcp_clients_limit 200`,
+		`bind_config_zone_info{type="primary",view="_default",zone="TEST_ZONE"} 1`,
+		`bind_config_zone_info{type="primary",view="_default",zone="broken.example"} 1`,
+		`bind_config_zone_info{type="forward",view="_default",zone="forward.example"} 1`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
 		}
-	}))
+	}
+	if m := `bind_recursive_clients_limit{kind="soft"}`; bytes.Contains(o, []byte(m)) {
+		t.Errorf("expected to not find metric %q in output\n%s", m, o)
+	}
+
+	o, err = collect(newConfigCollector(promslog.NewNopLogger(), newConfigFile("fixtures/namedconf/missing.conf"), true, seriesFilter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte(`bind_config_up 0`); !bytes.Contains(o, want) {
+		t.Errorf("expected to find metric %q in output\n%s", want, o)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("named.conf", `include "zones.conf";`)
+	write("zones.conf", `zone "example.com" { type primary; };`)
+
+	f := newConfigFile(filepath.Join(dir, "named.conf"))
+	first, err := f.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := f.load(); err != nil || c != first {
+		t.Errorf("expected unchanged configuration to be cached, got %p, %v", c, err)
+	}
+
+	write("zones.conf", `zone "example.com" { type primary; }; zone "example.org" { type primary; };`)
+	c, err := f.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == first || len(c.Zones()) != 2 {
+		t.Errorf("expected changed include to be parsed again, got zones %+v", c.Zones())
+	}
+
+	if err := os.Remove(filepath.Join(dir, "zones.conf")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.load(); err == nil {
+		t.Error("expected error for removed include")
+	}
+}
+
+func TestQueryLogCollector(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newQueryLogCollector(promslog.NewNopLogger(), 2, time.Minute)
+	c.now = func() time.Time { return now }
+	c.windowEnd = now.Add(time.Minute)
+
+	b, err := os.ReadFile("fixtures/querylog/query.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		c.observe(l)
+	}
+
+	o, err := collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_querylog_queries_total{qtype="A",transport="udp",view="internal"} 1`,
+		`bind_querylog_queries_total{qtype="A",transport="udp",view="external"} 1`,
+		`bind_querylog_queries_total{qtype="MX",transport="tcp",view="internal"} 1`,
+		`bind_querylog_queries_total{qtype="TXT",transport="tcp",view="_default"} 1`,
+		`bind_querylog_unparsed_lines_total 1`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+	// The top clients are only exported once the window is complete.
+	if bytes.Contains(o, []byte("bind_querylog_top_")) {
+		t.Errorf("unexpected top metrics before the end of the window\n%s", o)
+	}
+
+	now = now.Add(time.Minute)
+	if o, err = collect(c); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_querylog_top_client_queries{client="192.0.2.1",rank="1"} 3`,
+		`bind_querylog_top_client_queries{client="198.51.100.7",rank="2"} 1`,
+		`bind_querylog_top_name_queries{name="example.com",rank="1"} 3`,
+		`bind_querylog_top_name_queries{name="example.org",rank="2"} 1`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+
+	// A window without queries clears the top metrics.
+	now = now.Add(time.Minute)
+	if o, err = collect(c); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(o, []byte("bind_querylog_top_")) {
+		t.Errorf("unexpected top metrics after an empty window\n%s", o)
+	}
+}
+
+func TestDNSTapCollector(t *testing.T) {
+	l, err := dnstap.Listen(filepath.Join(t.TempDir(), "dnstap.sock"), 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	c := newDNSTapCollector(promslog.NewNopLogger(), 24, 56, 1, 1)
+	go c.serve(l)
+
+	conn, err := net.Dial("unix", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	w, err := dnstap.NewWriter(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A response to an AAAA query with rcode NXDOMAIN.
+	response := []byte{0x12, 0x34, 0x81, 0x83, 0, 1, 0, 0, 0, 0, 0, 0}
+	response = append(response, "\x07example\x03com\x00\x00\x1c\x00\x01"...)
+
+	qtime := time.Unix(1700000000, 0)
+	for _, m := range []dnstap.Message{
+		{Type: dnstap.ClientQuery, QueryAddress: net.IP{192, 0, 2, 1}, QueryTime: qtime},
+		{Type: dnstap.ClientQuery, QueryAddress: net.IP{192, 0, 2, 200}, QueryTime: qtime},
+		{Type: dnstap.ClientQuery, QueryAddress: net.IP{198, 51, 100, 1}, QueryTime: qtime},
+		{
+			Type:            dnstap.ClientResponse,
+			SocketProtocol:  dnstap.UDP,
+			QueryTime:       qtime,
+			ResponseTime:    qtime.Add(3 * time.Millisecond),
+			ResponseMessage: response,
+		},
+		{
+			Type:            dnstap.ResolverResponse,
+			ResponseAddress: net.ParseIP("2001:db8::53"),
+			QueryTime:       qtime,
+			ResponseTime:    qtime.Add(20 * time.Millisecond),
+		},
+		{
+			Type:            dnstap.ResolverResponse,
+			ResponseAddress: net.IP{203, 0, 113, 53},
+			QueryTime:       qtime,
+			ResponseTime:    qtime.Add(40 * time.Millisecond),
+		},
+	} {
+		if err := w.Write(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The stream is finished once all messages were handled.
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_dnstap_messages_total{type="CLIENT_QUERY"} 3`,
+		`bind_dnstap_client_queries_total{subnet="192.0.2.0/24"} 2`,
+		`bind_dnstap_client_queries_total{subnet="other"} 1`,
+		`bind_dnstap_response_duration_seconds_bucket{qtype="AAAA",rcode="NXDOMAIN",le="0.0032"} 1`,
+		`bind_dnstap_response_duration_seconds_bucket{qtype="AAAA",rcode="NXDOMAIN",le="0.0016"} 0`,
+		`bind_dnstap_response_size_bytes_count{transport="udp"} 1`,
+		`bind_dnstap_upstream_rtt_seconds_count{server="2001:db8::53"} 1`,
+		`bind_dnstap_upstream_rtt_seconds_count{server="other"} 1`,
+		`bind_dnstap_errors_total 0`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+}
+
+func TestDNSTapSocketOwnership(t *testing.T) {
+	gid := strconv.Itoa(os.Getgid())
+	for _, tc := range []struct {
+		mode, group string
+		wantMode    os.FileMode
+		wantGID     int
+		err         bool
+	}{
+		{wantGID: -1},
+		{mode: "0660", group: gid, wantMode: 0o660, wantGID: os.Getgid()},
+		{mode: "660", wantMode: 0o660, wantGID: -1},
+		{mode: "0990", err: true},
+		{mode: "17777", err: true},
+		{group: "no-such-group-bind-exporter", err: true},
+	} {
+		mode, gid, err := dnstapSocketOwnership(tc.mode, tc.group)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q, %q: expected error", tc.mode, tc.group)
+			}
+			continue
+		}
+		if err != nil || mode != tc.wantMode || gid != tc.wantGID {
+			t.Errorf("%q, %q: got %v, %d, %v, want %v, %d", tc.mode, tc.group, mode, gid, err, tc.wantMode, tc.wantGID)
+		}
+	}
+}
+
+func TestProbeCollector(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeServerFailure)
+		w.WriteMsg(m)
+	})}
+	go s.ActivateAndServe()
+	defer s.Shutdown()
+
+	path := filepath.Join(t.TempDir(), "probes.yml")
+	if err := os.WriteFile(path, []byte(`probes:
+  - name: resolve
+    query: example.com
+  - name: servfail
+    query: example.com
+    expect_rcode: SERVFAIL
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := probe.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newProbeCollector(promslog.NewNopLogger(), conf, pc.LocalAddr().String())
+	c.runOnce(context.Background())
+
+	o, err := collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_probe_success{probe="resolve"} 0`,
+		`bind_probe_success{probe="servfail"} 1`,
+		`bind_probe_dnssec_validated{probe="servfail"} 0`,
+		`bind_probe_duration_seconds{probe="resolve"}`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+}
+
+func TestBindExporterZoneLag(t *testing.T) {
+	const secret = "c2VjcmV0c2VjcmV0c2VjcmV0"
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &dns.Server{
+		PacketConn: pc,
+		TsigSecret: map[string]string{"xfr.": secret},
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			serial := uint32(130)
+			if r.IsTsig() != nil {
+				if w.TsigStatus() != nil {
+					m.SetRcode(r, dns.RcodeNotAuth)
+					w.WriteMsg(m)
+					return
+				}
+				// The signed primary is ahead.
+				serial = 140
+				m.SetTsig("xfr.", dns.HmacSHA256, 300, time.Now().Unix())
+			}
+			m.Answer = append(m.Answer, &dns.SOA{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+				Ns:  "ns.", Mbox: "hostmaster.", Serial: serial,
+			})
+			w.WriteMsg(m)
+		}),
+	}
+	go s.ActivateAndServe()
+	defer s.Shutdown()
+
+	_, port, _ := net.SplitHostPort(pc.LocalAddr().String())
+	config := filepath.Join(t.TempDir(), "named.conf")
+	if err := os.WriteFile(config, []byte(`
+key "xfr" { algorithm hmac-sha256; secret "`+secret+`"; };
+zone "TEST_ZONE" {
+	type secondary;
+	primaries port `+port+` { 127.0.0.1; };
+};
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := newJSONServer()
+	defer server.Close()
+	e := NewExporter(promslog.NewNopLogger(), testClient(t, "json", server.URL), []bind.StatisticGroup{bind.ZoneStats}, seriesFilter{})
+	e.config = newConfigFile(config)
+	e.lag = newZoneLag(promslog.NewNopLogger(), e.config, 200*time.Millisecond, seriesFilter{})
+	now := time.Unix(1700000000, 0)
+	e.lag.now = func() time.Time { return now }
+
+	// Nothing is exported before the primaries were queried.
+	o, err := collect(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := "bind_zone_primary_serial{"; bytes.Contains(o, []byte(m)) {
+		t.Errorf("expected to not find metric %q in output\n%s", m, o)
+	}
+
+	e.lag.query()
+	o, err = collect(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := net.JoinHostPort("127.0.0.1", port)
+	for _, m := range []string{
+		`bind_zone_primary_serial{primary="` + primary + `",view="_default",zone="TEST_ZONE"} 130`,
+		`bind_zone_serial_behind{view="_default",zone="TEST_ZONE"} 7`,
+		`bind_zone_behind_seconds{view="_default",zone="TEST_ZONE"} 0`,
+		"# TYPE bind_zone_serial gauge",
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if o, err = collect(e); err != nil {
+		t.Fatal(err)
+	}
+	if m := `bind_zone_behind_seconds{view="_default",zone="TEST_ZONE"} 60`; !bytes.Contains(o, []byte(m)) {
+		t.Errorf("expected to find metric %q in output\n%s", m, o)
+	}
+
+	serial, err := e.lag.querySerial("TEST_ZONE", namedconf.Primary{Address: primary, Key: "xfr"}, map[string]namedconf.Key{
+		"xfr": {Name: "xfr", Algorithm: "hmac-sha256", Secret: secret},
+	})
+	if err != nil || serial != 140 {
+		t.Errorf("want signed serial 140, got %d: %v", serial, err)
+	}
+}
+
+func TestSerialBehind(t *testing.T) {
+	for _, tc := range []struct {
+		local, primary, want uint32
+	}{
+		{123, 130, 7},
+		{130, 123, 0},
+		{123, 123, 0},
+		{4294967290, 5, 11},
+		{5, 4294967290, 0},
+		{0, 1 << 31, 0},
+	} {
+		if got := serialBehind(tc.local, tc.primary); got != tc.want {
+			t.Errorf("serialBehind(%d, %d): want %d, got %d", tc.local, tc.primary, tc.want, got)
+		}
+	}
+}
+
+func TestDNSSECCollector(t *testing.T) {
+	dir, err := filepath.Abs("fixtures/dnssec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(t.TempDir(), "named.conf")
+	if err := os.WriteFile(config, []byte(`
+options { directory "`+dir+`"; key-directory "keys"; };
+view "internal" {
+	zone "example.com" { type primary; file "example.com.db"; dnssec-policy default; inline-signing yes; masterfile-format text; };
+	zone "unsigned.example" { type primary; file "unsigned.example.db"; };
+	zone "missing.example" { type primary; file "missing.example.db"; dnssec-policy default; };
+};
+view "external" {
+	zone "example.com" { type primary; file "example.com.db"; dnssec-policy default; inline-signing yes; };
+};
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newDNSSECCollector(promslog.NewNopLogger(), config, dnssecSourceFile, "", time.Second, seriesFilter{})
+	if err := c.check(); err == nil || !strings.Contains(err.Error(), "view external") {
+		t.Errorf("expected error for inline-signing zone in raw format, got %v", err)
+	}
+	c.inspect()
+	o, err := collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_dnssec_inspect_success{view="internal",zone="example.com"} 1`,
+		`bind_dnssec_inspect_success{view="internal",zone="missing.example"} 0`,
+		`bind_dnssec_inspect_success{view="external",zone="example.com"} 0`,
+		`bind_dnssec_rrsig_min_expiry_timestamp_seconds{view="internal",zone="example.com"} 1.7935344e+09`,
+		`bind_dnssec_key_info{algorithm="ECDSAP256SHA256",keytag="2371",role="ksk",view="internal",zone="example.com"} 1`,
+		`bind_dnssec_key_info{algorithm="ECDSAP256SHA256",keytag="34505",role="csk",view="internal",zone="example.com"} 1`,
+		`bind_dnssec_key_info{algorithm="ECDSAP256SHA256",keytag="34505",role="csk",view="external",zone="example.com"} 1`,
+		`bind_dnssec_key_event_timestamp_seconds{event="retire",keytag="2371",view="internal",zone="example.com"} 1.7987616e+09`,
+		`bind_dnssec_key_event_timestamp_seconds{event="activate",keytag="34505",view="internal",zone="example.com"} 1.7672256e+09`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+	for _, m := range []string{
+		"unsigned.example",
+		`bind_dnssec_rrsig_min_expiry_timestamp_seconds{view="external"`,
+	} {
+		if bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to not find metric %q in output\n%s", m, o)
+		}
+	}
+
+	c.filter = seriesFilter{viewExclude: regexp.MustCompile("^external$")}
+	if err := c.check(); err != nil {
+		t.Errorf("expected zones in text format to pass the check, got %v", err)
+	}
+}
+
+func TestDNSSECCollectorTransfer(t *testing.T) {
+	const secret = "c2VjcmV0c2VjcmV0c2VjcmV0"
+	zoneRRs := func(path string) []dns.RR {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var rrs []dns.RR
+		zp := dns.NewZoneParser(f, "example.com.", "")
+		for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+			rrs = append(rrs, rr)
+		}
+		// A zone transfer starts and ends with the SOA record.
+		return append(rrs, rrs[0])
+	}
+	// The view of the internal key serves the signed zone, the one of the
+	// external key the unsigned zone.
+	views := map[string][]dns.RR{
+		"internal.": zoneRRs("fixtures/dnssec/example.com.db.signed"),
+		"external.": zoneRRs("fixtures/dnssec/example.com.db"),
+	}
+
+	var (
+		mu        sync.Mutex
+		transfers []string
+	)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &dns.Server{
+		Listener:   l,
+		TsigSecret: map[string]string{"internal.": secret, "external.": secret},
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			var key string
+			if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+				key = tsig.Hdr.Name
+			}
+			mu.Lock()
+			transfers = append(transfers, key+" "+r.Question[0].Name)
+			mu.Unlock()
+			rrs, ok := views[key]
+			if !ok {
+				m := new(dns.Msg)
+				m.SetRcode(r, dns.RcodeRefused)
+				w.WriteMsg(m)
+				return
+			}
+			ch := make(chan *dns.Envelope)
+			go func() {
+				ch <- &dns.Envelope{RR: rrs}
+				close(ch)
+			}()
+			new(dns.Transfer).Out(w, r, ch)
+			w.Close()
+		}),
+	}
+	go s.ActivateAndServe()
+	defer s.Shutdown()
+
+	dir, err := filepath.Abs("fixtures/dnssec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(t.TempDir(), "named.conf")
+	if err := os.WriteFile(config, []byte(`
+options { directory "`+dir+`"; key-directory "keys"; };
+key "internal" { algorithm hmac-sha256; secret "`+secret+`"; };
+key "external" { algorithm hmac-sha256; secret "`+secret+`"; };
+view "internal" {
+	match-clients { key "internal"; 10.0.0.0/8; };
+	zone "example.com" { type primary; file "example.com.db"; dnssec-policy default; };
+};
+view "external" {
+	match-clients { !key "internal"; key "external"; any; };
+	zone "example.com" { type primary; file "example.com.db"; dnssec-policy default; };
+};
+view "other" {
+	zone "example.com" { type primary; file "example.com.db"; dnssec-policy default; };
+	zone "single.example" { type primary; file "single.example.db"; dnssec-policy default; };
+};
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newDNSSECCollector(promslog.NewNopLogger(), config, dnssecSourceAXFR, l.Addr().String(), time.Second, seriesFilter{})
+	c.inspect()
+	o, err := collect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_dnssec_inspect_success{view="internal",zone="example.com"} 1`,
+		`bind_dnssec_inspect_success{view="external",zone="example.com"} 0`,
+		`bind_dnssec_inspect_success{view="other",zone="example.com"} 0`,
+		`bind_dnssec_inspect_success{view="other",zone="single.example"} 0`,
+		`bind_dnssec_rrsig_min_expiry_timestamp_seconds{view="internal",zone="example.com"} 1.7935344e+09`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+	for _, m := range []string{
+		`bind_dnssec_rrsig_min_expiry_timestamp_seconds{view="external"`,
+		`bind_dnssec_rrsig_min_expiry_timestamp_seconds{view="other"`,
+	} {
+		if bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to not find metric %q in output\n%s", m, o)
+		}
+	}
+
+	// The zone of the view without a key is only transferred if no other
+	// view has it.
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"internal. example.com.", "external. example.com.", " single.example."}; !reflect.DeepEqual(transfers, want) {
+		t.Errorf("want transfers %q, got %q", want, transfers)
+	}
 }
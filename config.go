@@ -0,0 +1,174 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/namedconf"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	configUp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "config", "up"),
+		"Was parsing the configuration file successful?",
+		nil, nil,
+	)
+	configZoneInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "config", "zone_info"),
+		"Zones configured in named.conf.",
+		[]string{"view", "zone", "type"}, nil,
+	)
+	zoneConfiguredNotLoaded = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "zone", "configured_not_loaded"),
+		"Zones configured in named.conf but missing from the zone statistics.",
+		[]string{"view", "zone"}, nil,
+	)
+)
+
+// configFile is a named.conf shared by the collectors using it. It is parsed
+// again once it or one of its includes changed, to pick up reconfigurations.
+type configFile struct {
+	path string
+
+	mu   sync.Mutex
+	conf *namedconf.Config
+}
+
+func newConfigFile(path string) *configFile {
+	return &configFile{path: path}
+}
+
+// load returns the parsed configuration, parsing it if it changed since it
+// was last parsed.
+func (f *configFile) load() (*namedconf.Config, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conf != nil && !changed(f.conf.Files) {
+		return f.conf, nil
+	}
+	conf, err := namedconf.ParseFile(f.path)
+	if err != nil {
+		f.conf = nil
+		return nil, err
+	}
+	f.conf = conf
+	return conf, nil
+}
+
+// changed returns whether any of the files was modified, replaced or removed.
+func changed(files map[string]os.FileInfo) bool {
+	for path, old := range files {
+		fi, err := os.Stat(path)
+		if err != nil || !os.SameFile(fi, old) || !fi.ModTime().Equal(old.ModTime()) || fi.Size() != old.Size() {
+			return true
+		}
+	}
+	return false
+}
+
+// configCollector exports information parsed from named.conf.
+type configCollector struct {
+	file *configFile
+	// limits exports the client limits. They are taken from rndc instead if
+	// the control channel is configured.
+	limits bool
+	filter seriesFilter
+	logger *slog.Logger
+}
+
+func newConfigCollector(logger *slog.Logger, file *configFile, limits bool, f seriesFilter) *configCollector {
+	return &configCollector{file: file, limits: limits, filter: f, logger: logger}
+}
+
+// Describe implements prometheus.Collector.
+func (c *configCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- configUp
+	ch <- configZoneInfo
+	if c.limits {
+		ch <- recursiveClientsLimit
+		ch <- tcpClientsLimit
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *configCollector) Collect(ch chan<- prometheus.Metric) {
+	conf, err := c.file.load()
+	if err != nil {
+		c.logger.Error("Couldn't parse configuration file", "err", err)
+		ch <- prometheus.MustNewConstMetric(configUp, prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(configUp, prometheus.GaugeValue, 1)
+
+	for _, z := range conf.Zones() {
+		if !c.filter.keepView(z.View) || !c.filter.keepZone(z.Name) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			configZoneInfo, prometheus.GaugeValue, 1, z.View, z.Name, z.Type,
+		)
+	}
+
+	if c.limits {
+		// The soft limit depends on the number of CPUs of named and is only
+		// reported by rndc.
+		l := conf.Limits()
+		ch <- prometheus.MustNewConstMetric(
+			recursiveClientsLimit, prometheus.GaugeValue, float64(l.RecursiveClients), "hard",
+		)
+		ch <- prometheus.MustNewConstMetric(tcpClientsLimit, prometheus.GaugeValue, float64(l.TCPClients))
+	}
+}
+
+// collectConfiguredZones compares the zones of the configuration with the
+// zone statistics.
+func (e *Exporter) collectConfiguredZones(ch chan<- prometheus.Metric, s *bind.Statistics) {
+	conf, err := e.config.load()
+	if err != nil {
+		e.logger.Error("Couldn't parse configuration file", "err", err)
+		return
+	}
+	e.collectNotLoaded(ch, conf, s)
+	if e.lag != nil {
+		e.lag.collect(ch, s)
+	}
+}
+
+// collectNotLoaded exports the zones of the configuration which failed to
+// load, i.e. are reported without serial or are missing from the zone
+// statistics. Only zones of class IN are compared, as the statistics channel
+// doesn't report others.
+func (e *Exporter) collectNotLoaded(ch chan<- prometheus.Metric, conf *namedconf.Config, s *bind.Statistics) {
+	loaded := map[[2]string]bool{}
+	for _, v := range s.ZoneViews {
+		for _, z := range v.ZoneData {
+			loaded[[2]string{v.Name, z.Name}] = z.Loaded()
+		}
+	}
+	for _, z := range conf.Zones() {
+		if z.Class != "IN" || !z.Loaded() || !e.filter.keepView(z.View) || !e.filter.keepZone(z.Name) {
+			continue
+		}
+		v := 0.
+		if !loaded[[2]string{z.View, z.Name}] {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(zoneConfiguredNotLoaded, prometheus.GaugeValue, v, z.View, z.Name)
+	}
+}
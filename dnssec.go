@@ -0,0 +1,266 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/dnssec"
+	"github.com/prometheus-community/bind_exporter/bind/namedconf"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sources of the signed zones.
+const (
+	dnssecSourceAXFR = "axfr"
+	dnssecSourceFile = "file"
+)
+
+var (
+	dnssecInspectSuccess = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "dnssec", "inspect_success"),
+		"Whether the signatures of the zone could be inspected.",
+		[]string{"view", "zone"}, nil,
+	)
+	dnssecRRSIGMinExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "dnssec", "rrsig_min_expiry_timestamp_seconds"),
+		"Expiration time of the signature of the zone expiring first.",
+		[]string{"view", "zone"}, nil,
+	)
+	dnssecKeyInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "dnssec", "key_info"),
+		"DNSSEC keys of the zone in the key directory.",
+		[]string{"view", "zone", "keytag", "algorithm", "role"}, nil,
+	)
+	dnssecKeyEvent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "dnssec", "key_event_timestamp_seconds"),
+		"Time the key is scheduled to be or was published, activated, retired or deleted.",
+		[]string{"view", "zone", "keytag", "event"}, nil,
+	)
+)
+
+// dnssecZone is the result of inspecting a signed zone.
+type dnssecZone struct {
+	expiry time.Time
+	err    error
+	keys   []dnssec.Key
+}
+
+// dnssecCollector inspects the signatures and keys of the signed zones
+// configured in named.conf in the background, as transferring zones is too
+// expensive to do on every scrape.
+type dnssecCollector struct {
+	config  string
+	source  string
+	addr    string
+	timeout time.Duration
+	filter  seriesFilter
+	logger  *slog.Logger
+
+	mu sync.Mutex
+	// zones holds the inspected zones by view and name.
+	zones map[[2]string]dnssecZone
+}
+
+func newDNSSECCollector(logger *slog.Logger, config, source, addr string, timeout time.Duration, f seriesFilter) *dnssecCollector {
+	return &dnssecCollector{
+		config:  config,
+		source:  source,
+		addr:    addr,
+		timeout: timeout,
+		filter:  f,
+		logger:  logger,
+		zones:   map[[2]string]dnssecZone{},
+	}
+}
+
+// run inspects the zones every interval until ctx is done.
+func (c *dnssecCollector) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.inspect()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// signedZones returns the signed zones of the configuration to inspect.
+func (c *dnssecCollector) signedZones(conf *namedconf.Config) []namedconf.Zone {
+	var zones []namedconf.Zone
+	for _, z := range conf.Zones() {
+		if !(z.Signed || z.InlineSigning) || z.Class != "IN" || !c.filter.keepView(z.View) || !c.filter.keepZone(z.Name) {
+			continue
+		}
+		zones = append(zones, z)
+	}
+	return zones
+}
+
+// check verifies that the signed zones of the configuration can be
+// inspected with the configured source.
+func (c *dnssecCollector) check() error {
+	if c.source != dnssecSourceFile {
+		return nil
+	}
+	conf, err := namedconf.ParseFile(c.config)
+	if err != nil {
+		return err
+	}
+	for _, z := range c.signedZones(conf) {
+		if _, err := signedZoneFile(z); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signedZoneFile returns the path of the file holding the signed version of
+// a zone, which needs to be in text format to be read.
+func signedZoneFile(z namedconf.Zone) (string, error) {
+	path, format := z.File, z.MasterfileFormat
+	if z.InlineSigning {
+		path += ".signed"
+	}
+	if format == "" {
+		// named writes the files it maintains itself in raw format unless
+		// configured otherwise.
+		format = "text"
+		if z.InlineSigning || z.Type == "secondary" || z.Type == "mirror" {
+			format = "raw"
+		}
+	}
+	if z.File == "" {
+		return "", fmt.Errorf("signed zone %s of view %s has no file, use --bind.dnssec.source=axfr", z.Name, z.View)
+	}
+	if format != "text" {
+		return "", fmt.Errorf("signed zone %s of view %s isn't written in text format, configure masterfile-format text or use --bind.dnssec.source=axfr", z.Name, z.View)
+	}
+	return path, nil
+}
+
+// inspect inspects all signed zones once. Transfers of a zone configured in
+// several views are signed with the TSIG key in the match-clients of each
+// view, so that named answers them from that view, and fail for views
+// without one.
+func (c *dnssecCollector) inspect() {
+	conf, err := namedconf.ParseFile(c.config)
+	if err != nil {
+		c.logger.Error("Couldn't parse configuration file", "err", err)
+		return
+	}
+
+	views := map[string]int{}
+	for _, z := range conf.Zones() {
+		if z.Loaded() && z.Class == "IN" {
+			views[z.Name]++
+		}
+	}
+	keys := conf.Keys()
+	zones := map[[2]string]dnssecZone{}
+	// Transfers by key and zone.
+	transfers := map[[2]string]dnssecZone{}
+	for _, z := range c.signedZones(conf) {
+		var r dnssecZone
+		switch c.source {
+		case dnssecSourceFile:
+			var path string
+			if path, r.err = signedZoneFile(z); r.err == nil {
+				r.expiry, r.err = dnssec.FileExpiry(path, z.Name)
+			}
+		default:
+			var key *namedconf.Key
+			if key, r.err = transferKey(z, views[z.Name], keys); r.err != nil {
+				break
+			}
+			t, ok := transfers[[2]string{z.ViewKey, z.Name}]
+			if !ok {
+				t.expiry, t.err = dnssec.TransferExpiry(c.addr, z.Name, key, c.timeout)
+				transfers[[2]string{z.ViewKey, z.Name}] = t
+			}
+			r.expiry, r.err = t.expiry, t.err
+		}
+		if r.err != nil {
+			c.logger.Error("Couldn't inspect zone signatures", "view", z.View, "zone", z.Name, "err", r.err)
+		}
+		if r.keys, err = dnssec.ReadKeys(z.KeyDirectory, z.Name); err != nil {
+			c.logger.Error("Couldn't read zone keys", "view", z.View, "zone", z.Name, "err", err)
+		}
+		zones[[2]string{z.View, z.Name}] = r
+	}
+
+	c.mu.Lock()
+	c.zones = zones
+	c.mu.Unlock()
+}
+
+// transferKey returns the key to sign the transfer of a zone configured in
+// the given number of views with. Without a key, named answers from the
+// first view matching the exporter, which is only known to be the view of
+// the zone if no other view has it.
+func transferKey(z namedconf.Zone, views int, keys map[string]namedconf.Key) (*namedconf.Key, error) {
+	if z.ViewKey == "" {
+		if views > 1 {
+			return nil, fmt.Errorf("can't select view %s for the transfer of zone %s, add a TSIG key to its match-clients or use --bind.dnssec.source=file", z.View, z.Name)
+		}
+		return nil, nil
+	}
+	k, ok := keys[z.ViewKey]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", z.ViewKey)
+	}
+	return &k, nil
+}
+
+// Describe implements prometheus.Collector.
+func (c *dnssecCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dnssecInspectSuccess
+	ch <- dnssecRRSIGMinExpiry
+	ch <- dnssecKeyInfo
+	ch <- dnssecKeyEvent
+}
+
+// Collect implements prometheus.Collector.
+func (c *dnssecCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, z := range c.zones {
+		view, zone := name[0], name[1]
+		ch <- prometheus.MustNewConstMetric(
+			dnssecInspectSuccess, prometheus.GaugeValue, float64(boolToUint(z.err == nil)), view, zone,
+		)
+		if z.err == nil {
+			ch <- prometheus.MustNewConstMetric(
+				dnssecRRSIGMinExpiry, prometheus.GaugeValue, float64(z.expiry.Unix()), view, zone,
+			)
+		}
+		for _, k := range z.keys {
+			tag := strconv.Itoa(int(k.KeyTag))
+			ch <- prometheus.MustNewConstMetric(dnssecKeyInfo, prometheus.GaugeValue, 1, view, zone, tag, k.Algorithm, k.Role)
+			for _, e := range dnssec.Events {
+				if t, ok := k.Events[e]; ok {
+					ch <- prometheus.MustNewConstMetric(dnssecKeyEvent, prometheus.GaugeValue, float64(t.Unix()), view, zone, tag, e)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus-community/bind_exporter/bind/dnstap"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// otherLabel replaces label values beyond the configured maximum.
+const otherLabel = "other"
+
+// dnstapCollector exports metrics of the messages named logs via dnstap.
+type dnstapCollector struct {
+	messages       *prometheus.CounterVec
+	errors         prometheus.Counter
+	duration       *prometheus.HistogramVec
+	responseSize   *prometheus.HistogramVec
+	clientQueries  *prometheus.CounterVec
+	upstreamRTT    *prometheus.HistogramVec
+	ipv4Mask       net.IPMask
+	ipv6Mask       net.IPMask
+	subnets        *boundedLabel
+	upstreamServer *boundedLabel
+	logger         *slog.Logger
+}
+
+func newDNSTapCollector(logger *slog.Logger, ipv4PrefixLength, ipv6PrefixLength, maxSubnets, maxServers int) *dnstapCollector {
+	return &dnstapCollector{
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dnstap",
+			Name:      "messages_total",
+			Help:      "Number of dnstap messages received.",
+		}, []string{"type"}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dnstap",
+			Name:      "errors_total",
+			Help:      "Number of dnstap connections failed and messages which couldn't be decoded.",
+		}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "dnstap",
+			Name:      "response_duration_seconds",
+			Help:      "Time between receiving a client query and sending the response.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}, []string{"qtype", "rcode"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "dnstap",
+			Name:      "response_size_bytes",
+			Help:      "Size of the responses sent to clients.",
+			Buckets:   prometheus.ExponentialBuckets(32, 2, 12),
+		}, []string{"transport"}),
+		clientQueries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dnstap",
+			Name:      "client_queries_total",
+			Help:      "Number of queries received from clients, aggregated by subnet.",
+		}, []string{"subnet"}),
+		upstreamRTT: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "dnstap",
+			Name:      "upstream_rtt_seconds",
+			Help:      "Round-trip time of queries sent by the resolver to upstream servers.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"server"}),
+		ipv4Mask:       net.CIDRMask(ipv4PrefixLength, 32),
+		ipv6Mask:       net.CIDRMask(ipv6PrefixLength, 128),
+		subnets:        newBoundedLabel(maxSubnets),
+		upstreamServer: newBoundedLabel(maxServers),
+		logger:         logger,
+	}
+}
+
+// serve accepts dnstap connections on l until it is closed.
+func (c *dnstapCollector) serve(l net.Listener) error {
+	s := &dnstap.Server{
+		Handle: c.observe,
+		Error: func(err error) {
+			c.errors.Inc()
+			c.logger.Debug("Error receiving dnstap messages", "err", err)
+		},
+	}
+	return s.Serve(l)
+}
+
+// dnstapSocketOwnership parses the mode and group of the dnstap socket. An
+// empty mode is returned as zero, an empty group as -1.
+func dnstapSocketOwnership(mode, group string) (os.FileMode, int, error) {
+	var m uint64
+	if mode != "" {
+		var err error
+		if m, err = strconv.ParseUint(mode, 8, 32); err != nil || m&^uint64(os.ModePerm) != 0 {
+			return 0, 0, fmt.Errorf("invalid socket mode %q", mode)
+		}
+	}
+	gid := -1
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			if g, err = user.LookupGroupId(group); err != nil {
+				return 0, 0, fmt.Errorf("unknown socket group %q", group)
+			}
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return 0, 0, fmt.Errorf("invalid ID of socket group %q: %s", group, g.Gid)
+		}
+	}
+	return os.FileMode(m), gid, nil
+}
+
+// observe updates the metrics with a received message.
+func (c *dnstapCollector) observe(m dnstap.Message) {
+	c.messages.WithLabelValues(m.Type.String()).Inc()
+
+	switch m.Type {
+	case dnstap.ClientQuery:
+		if ip := c.subnet(m.QueryAddress); ip != "" {
+			c.clientQueries.WithLabelValues(c.subnets.value(ip)).Inc()
+		}
+	case dnstap.ClientResponse:
+		if m.ResponseMessage == nil {
+			return
+		}
+		c.responseSize.WithLabelValues(m.SocketProtocol.String()).Observe(float64(len(m.ResponseMessage)))
+		h, err := dnstap.ParseHeader(m.ResponseMessage)
+		if err != nil {
+			c.errors.Inc()
+			return
+		}
+		if !m.QueryTime.IsZero() && !m.ResponseTime.IsZero() {
+			c.duration.WithLabelValues(dnstap.QTypeName(h.QType), dnstap.RcodeName(h.Rcode)).
+				Observe(m.ResponseTime.Sub(m.QueryTime).Seconds())
+		}
+	case dnstap.ResolverResponse:
+		if m.ResponseAddress == nil || m.QueryTime.IsZero() || m.ResponseTime.IsZero() {
+			return
+		}
+		c.upstreamRTT.WithLabelValues(c.upstreamServer.value(m.ResponseAddress.String())).
+			Observe(m.ResponseTime.Sub(m.QueryTime).Seconds())
+	}
+}
+
+// subnet returns the subnet of a client address in CIDR notation.
+func (c *dnstapCollector) subnet(ip net.IP) string {
+	mask := c.ipv6Mask
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, mask = ip4, c.ipv4Mask
+	} else if len(ip) != net.IPv6len {
+		return ""
+	}
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
+
+// Describe implements prometheus.Collector.
+func (c *dnstapCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.messages.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+	c.responseSize.Describe(ch)
+	c.clientQueries.Describe(ch)
+	c.upstreamRTT.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *dnstapCollector) Collect(ch chan<- prometheus.Metric) {
+	c.messages.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+	c.responseSize.Collect(ch)
+	c.clientQueries.Collect(ch)
+	c.upstreamRTT.Collect(ch)
+}
+
+// boundedLabel limits the number of distinct values of a label. Values seen
+// after the limit was reached are replaced by otherLabel.
+type boundedLabel struct {
+	max int
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newBoundedLabel(max int) *boundedLabel {
+	return &boundedLabel{max: max, seen: map[string]struct{}{}}
+}
+
+func (b *boundedLabel) value(v string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.seen[v]; ok {
+		return v
+	}
+	if len(b.seen) >= b.max {
+		return otherLabel
+	}
+	b.seen[v] = struct{}{}
+	return v
+}
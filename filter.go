@@ -0,0 +1,179 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"regexp"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// internalView is the name of the view BIND creates for its built-in CHAOS
+// zones.
+const internalView = "_bind"
+
+// seriesFilter controls the cardinality of the exported metrics.
+type seriesFilter struct {
+	// viewInclude and viewExclude restrict the views exported. A nil
+	// expression doesn't filter anything.
+	viewInclude *regexp.Regexp
+	viewExclude *regexp.Regexp
+	// zoneInclude and zoneExclude restrict the zones exported.
+	zoneInclude *regexp.Regexp
+	zoneExclude *regexp.Regexp
+	// dropInternalView removes the internal _bind view.
+	dropInternalView bool
+	// maxSeries limits the number of series exported per metric family in a
+	// single scrape. Zero means no limit.
+	maxSeries int
+	// zoneDNSSEC keeps the per key DNSSEC signing counters of zones.
+	zoneDNSSEC bool
+	// rawCacheTypes exports the cache RRset types as reported by BIND instead
+	// of splitting their state into a label.
+	rawCacheTypes bool
+}
+
+func (f seriesFilter) keepView(name string) bool {
+	if f.dropInternalView && name == internalView {
+		return false
+	}
+	return match(name, f.viewInclude, f.viewExclude)
+}
+
+func (f seriesFilter) keepZone(name string) bool {
+	return match(name, f.zoneInclude, f.zoneExclude)
+}
+
+func match(s string, include, exclude *regexp.Regexp) bool {
+	if include != nil && !include.MatchString(s) {
+		return false
+	}
+	if exclude != nil && exclude.MatchString(s) {
+		return false
+	}
+	return true
+}
+
+// apply removes the views and zones not matching the filter from s.
+func (f seriesFilter) apply(s *bind.Statistics) {
+	views := s.Views[:0]
+	for _, v := range s.Views {
+		if f.keepView(v.Name) {
+			views = append(views, v)
+		}
+	}
+	s.Views = views
+
+	zoneViews := s.ZoneViews[:0]
+	for _, v := range s.ZoneViews {
+		if !f.keepView(v.Name) {
+			continue
+		}
+		zones := v.ZoneData[:0]
+		for _, z := range v.ZoneData {
+			if !f.keepZone(z.Name) {
+				continue
+			}
+			if !f.zoneDNSSEC {
+				z.DNSSECSign, z.DNSSECRefresh = nil, nil
+			}
+			zones = append(zones, z)
+		}
+		v.ZoneData = zones
+		zoneViews = append(zoneViews, v)
+	}
+	s.ZoneViews = zoneViews
+
+	transferViews := s.TransferViews[:0]
+	for _, v := range s.TransferViews {
+		if !f.keepView(v.Name) {
+			continue
+		}
+		transfers := v.Transfers[:0]
+		for _, t := range v.Transfers {
+			if f.keepZone(t.Zone) {
+				transfers = append(transfers, t)
+			}
+		}
+		v.Transfers = transfers
+		transferViews = append(transferViews, v)
+	}
+	s.TransferViews = transferViews
+}
+
+// limit returns a channel forwarding metrics to ch until a metric family
+// reaches the configured maximum number of series. Series beyond the limit are
+// counted in dropped. The returned function must be called once all metrics
+// have been sent.
+func (f seriesFilter) limit(ch chan<- prometheus.Metric, dropped *prometheus.CounterVec) (chan<- prometheus.Metric, func()) {
+	if f.maxSeries <= 0 {
+		return ch, func() {}
+	}
+
+	out := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		series := map[*prometheus.Desc]int{}
+		names := map[*prometheus.Desc]string{}
+		for m := range out {
+			d := m.Desc()
+			if series[d] >= f.maxSeries {
+				if _, ok := names[d]; !ok {
+					names[d] = metricName(m)
+				}
+				dropped.WithLabelValues(names[d]).Inc()
+				continue
+			}
+			series[d]++
+			ch <- m
+		}
+	}()
+
+	return out, func() {
+		close(out)
+		<-done
+	}
+}
+
+// metricName returns the name of the metric family of m, or an empty string
+// if m is invalid. It gathers m from a temporary registry since descriptors
+// don't expose their name.
+func metricName(m prometheus.Metric) string {
+	r := prometheus.NewPedanticRegistry()
+	if err := r.Register(metricCollector{m}); err != nil {
+		return ""
+	}
+	mfs, err := r.Gather()
+	if err != nil || len(mfs) == 0 {
+		return ""
+	}
+	return mfs[0].GetName()
+}
+
+// metricCollector collects a single metric.
+type metricCollector struct {
+	metric prometheus.Metric
+}
+
+// Describe implements prometheus.Collector.
+func (c metricCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.metric.Desc()
+}
+
+// Collect implements prometheus.Collector.
+func (c metricCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- c.metric
+}
@@ -0,0 +1,406 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlptest decodes OTLP export requests for testing the exporter.
+package otlptest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/otlp"
+	"github.com/prometheus-community/bind_exporter/internal/wire"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the protobuf messages of the metrics service.
+const (
+	exportRequestResourceMetrics protowire.Number = 1
+
+	resourceMetricsResource     protowire.Number = 1
+	resourceMetricsScopeMetrics protowire.Number = 2
+
+	resourceAttributes protowire.Number = 1
+
+	keyValueKey   protowire.Number = 1
+	keyValueValue protowire.Number = 2
+
+	anyValueString protowire.Number = 1
+
+	scopeMetricsScope   protowire.Number = 1
+	scopeMetricsMetrics protowire.Number = 2
+
+	scopeName    protowire.Number = 1
+	scopeVersion protowire.Number = 2
+
+	metricName        protowire.Number = 1
+	metricDescription protowire.Number = 2
+	metricUnit        protowire.Number = 3
+	metricGauge       protowire.Number = 5
+	metricSum         protowire.Number = 7
+	metricHistogram   protowire.Number = 9
+	metricSummary     protowire.Number = 11
+
+	// The data points of gauges, sums, histograms and summaries.
+	dataPoints     protowire.Number = 1
+	sumIsMonotonic protowire.Number = 3
+
+	pointStart protowire.Number = 2
+	pointTime  protowire.Number = 3
+
+	numberPointDouble     protowire.Number = 4
+	numberPointInt        protowire.Number = 6
+	numberPointAttributes protowire.Number = 7
+
+	histogramPointCount        protowire.Number = 4
+	histogramPointSum          protowire.Number = 5
+	histogramPointBucketCounts protowire.Number = 6
+	histogramPointBounds       protowire.Number = 7
+	histogramPointAttributes   protowire.Number = 9
+
+	summaryPointCount      protowire.Number = 4
+	summaryPointSum        protowire.Number = 5
+	summaryPointQuantiles  protowire.Number = 6
+	summaryPointAttributes protowire.Number = 7
+
+	quantileQuantile protowire.Number = 1
+	quantileValue    protowire.Number = 2
+)
+
+// field is a field of a protobuf message with its value for varint and
+// fixed fields, and its bytes for length-delimited fields.
+type field struct {
+	num protowire.Number
+	typ protowire.Type
+	v   uint64
+	b   []byte
+}
+
+func parse(b []byte) ([]field, error) {
+	var fs []field
+	err := wire.Fields(b, func(num protowire.Number, typ protowire.Type, v uint64, b []byte) {
+		fs = append(fs, field{num: num, typ: typ, v: v, b: b})
+	})
+	return fs, err
+}
+
+// Unmarshal decodes a serialized ExportMetricsServiceRequest protobuf. The
+// metrics of all resources and scopes are returned with the attributes and
+// scope of the first ones. Metric types other than gauges, sums, histograms
+// and summaries are skipped.
+func Unmarshal(b []byte) (otlp.Request, error) {
+	var r otlp.Request
+	fs, err := parse(b)
+	if err != nil {
+		return r, err
+	}
+	first := true
+	for _, f := range fs {
+		if f.num != exportRequestResourceMetrics {
+			continue
+		}
+		rms, err := parse(f.b)
+		if err != nil {
+			return r, err
+		}
+		for _, f := range rms {
+			switch f.num {
+			case resourceMetricsResource:
+				attrs, err := unmarshalAttributes(f.b, resourceAttributes)
+				if err != nil {
+					return r, err
+				}
+				if first {
+					r.Resource = attrs
+				}
+			case resourceMetricsScopeMetrics:
+				if err := unmarshalScopeMetrics(f.b, &r, first); err != nil {
+					return r, err
+				}
+			}
+		}
+		first = false
+	}
+	return r, nil
+}
+
+func unmarshalScopeMetrics(b []byte, r *otlp.Request, first bool) error {
+	fs, err := parse(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fs {
+		switch f.num {
+		case scopeMetricsScope:
+			if !first || r.ScopeName != "" {
+				continue
+			}
+			scope, err := parse(f.b)
+			if err != nil {
+				return err
+			}
+			for _, f := range scope {
+				switch f.num {
+				case scopeName:
+					r.ScopeName = string(f.b)
+				case scopeVersion:
+					r.ScopeVersion = string(f.b)
+				}
+			}
+		case scopeMetricsMetrics:
+			m, ok, err := unmarshalMetric(f.b)
+			if err != nil {
+				return err
+			}
+			if ok {
+				r.Metrics = append(r.Metrics, m)
+			}
+		}
+	}
+	return nil
+}
+
+func unmarshalMetric(b []byte) (otlp.Metric, bool, error) {
+	var (
+		m    otlp.Metric
+		data []byte
+		ok   bool
+	)
+	fs, err := parse(b)
+	if err != nil {
+		return m, false, err
+	}
+	for _, f := range fs {
+		switch f.num {
+		case metricName:
+			m.Name = string(f.b)
+		case metricDescription:
+			m.Description = string(f.b)
+		case metricUnit:
+			m.Unit = string(f.b)
+		case metricGauge:
+			m.Type, data, ok = otlp.Gauge, f.b, true
+		case metricSum:
+			m.Type, data, ok = otlp.Sum, f.b, true
+		case metricHistogram:
+			m.Type, data, ok = otlp.Histogram, f.b, true
+		case metricSummary:
+			m.Type, data, ok = otlp.Summary, f.b, true
+		}
+	}
+	if !ok {
+		return m, false, nil
+	}
+	fs, err = parse(data)
+	if err != nil {
+		return m, false, err
+	}
+	for _, f := range fs {
+		switch {
+		case f.num == dataPoints:
+			p, err := unmarshalPoint(m.Type, f.b)
+			if err != nil {
+				return m, false, err
+			}
+			m.Points = append(m.Points, p)
+		case f.num == sumIsMonotonic && m.Type == otlp.Sum:
+			m.Monotonic = protowire.DecodeBool(f.v)
+		}
+	}
+	return m, true, nil
+}
+
+func unmarshalPoint(t otlp.MetricType, b []byte) (otlp.Point, error) {
+	var p otlp.Point
+	attributes := numberPointAttributes
+	switch t {
+	case otlp.Histogram:
+		attributes = histogramPointAttributes
+	case otlp.Summary:
+		attributes = summaryPointAttributes
+	}
+	fs, err := parse(b)
+	if err != nil {
+		return p, err
+	}
+	for _, f := range fs {
+		switch {
+		case f.num == pointStart:
+			p.Start = time.Unix(0, int64(f.v))
+		case f.num == pointTime:
+			p.Time = time.Unix(0, int64(f.v))
+		case f.num == attributes:
+			a, err := unmarshalAttribute(f.b)
+			if err != nil {
+				return p, err
+			}
+			p.Attributes = append(p.Attributes, a)
+		case t == otlp.Gauge || t == otlp.Sum:
+			switch f.num {
+			case numberPointDouble:
+				p.Value = math.Float64frombits(f.v)
+			case numberPointInt:
+				p.Value = float64(int64(f.v))
+			}
+		case t == otlp.Histogram:
+			switch f.num {
+			case histogramPointCount:
+				p.Count = f.v
+			case histogramPointSum:
+				p.Sum = math.Float64frombits(f.v)
+			case histogramPointBucketCounts:
+				p.BucketCounts = append(p.BucketCounts, fixed64s(f)...)
+			case histogramPointBounds:
+				for _, v := range fixed64s(f) {
+					p.Bounds = append(p.Bounds, math.Float64frombits(v))
+				}
+			}
+		case t == otlp.Summary:
+			switch f.num {
+			case summaryPointCount:
+				p.Count = f.v
+			case summaryPointSum:
+				p.Sum = math.Float64frombits(f.v)
+			case summaryPointQuantiles:
+				qs, err := parse(f.b)
+				if err != nil {
+					return p, err
+				}
+				var q otlp.Quantile
+				for _, f := range qs {
+					switch f.num {
+					case quantileQuantile:
+						q.Quantile = math.Float64frombits(f.v)
+					case quantileValue:
+						q.Value = math.Float64frombits(f.v)
+					}
+				}
+				p.Quantiles = append(p.Quantiles, q)
+			}
+		}
+	}
+	return p, nil
+}
+
+// fixed64s returns the values of a packed or unpacked repeated fixed64 field.
+func fixed64s(f field) []uint64 {
+	if f.typ == protowire.Fixed64Type {
+		return []uint64{f.v}
+	}
+	var vs []uint64
+	b := f.b
+	for len(b) >= 8 {
+		v, n := protowire.ConsumeFixed64(b)
+		vs = append(vs, v)
+		b = b[n:]
+	}
+	return vs
+}
+
+func unmarshalAttributes(b []byte, num protowire.Number) ([]otlp.Attribute, error) {
+	fs, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	var attrs []otlp.Attribute
+	for _, f := range fs {
+		if f.num != num {
+			continue
+		}
+		a, err := unmarshalAttribute(f.b)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, a)
+	}
+	return attrs, nil
+}
+
+// unmarshalAttribute decodes a KeyValue. Values other than strings are left
+// empty.
+func unmarshalAttribute(b []byte) (otlp.Attribute, error) {
+	var a otlp.Attribute
+	fs, err := parse(b)
+	if err != nil {
+		return a, err
+	}
+	for _, f := range fs {
+		switch f.num {
+		case keyValueKey:
+			a.Key = string(f.b)
+		case keyValueValue:
+			vs, err := parse(f.b)
+			if err != nil {
+				return a, err
+			}
+			for _, v := range vs {
+				if v.num == anyValueString {
+					a.Value = string(v.b)
+				}
+			}
+		}
+	}
+	return a, nil
+}
+
+// Receiver is an http.Handler accepting export requests over HTTP and gRPC,
+// passing them to a function.
+type Receiver func(otlp.Request)
+
+// ServeHTTP implements http.Handler.
+func (f Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	grpc := r.Header.Get("Content-Type") == "application/grpc"
+	if grpc {
+		if len(b) < 5 || b[0] != 0 || int(binary.BigEndian.Uint32(b[1:5])) != len(b)-5 {
+			grpcStatus(w, 3, "invalid message frame")
+			return
+		}
+		b = b[5:]
+	}
+	req, err := Unmarshal(b)
+	if err != nil {
+		if grpc {
+			grpcStatus(w, 3, err.Error())
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	f(req)
+	if !grpc {
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		return
+	}
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.Header().Set("Content-Type", "application/grpc")
+	// An empty response message.
+	w.Write([]byte{0, 0, 0, 0, 0})
+	w.Header().Set("Grpc-Status", "0")
+}
+
+// grpcStatus writes a response without a message with a non-OK status.
+func grpcStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", fmt.Sprint(code))
+	w.Header().Set("Grpc-Message", msg)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remotewritetest decodes remote-write requests for testing the
+// exporter.
+package remotewritetest
+
+import (
+	"io"
+	"math"
+	"net/http"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus-community/bind_exporter/bind/remotewrite"
+	"github.com/prometheus-community/bind_exporter/internal/wire"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the WriteRequest, TimeSeries, Label and Sample protobuf
+// messages.
+const (
+	writeRequestTimeSeries protowire.Number = 1
+
+	timeSeriesLabels  protowire.Number = 1
+	timeSeriesSamples protowire.Number = 2
+
+	labelName  protowire.Number = 1
+	labelValue protowire.Number = 2
+
+	sampleValue     protowire.Number = 1
+	sampleTimestamp protowire.Number = 2
+)
+
+// Unmarshal decodes a serialized WriteRequest protobuf. Fields other than
+// the time series, like metadata, are ignored.
+func Unmarshal(b []byte) ([]remotewrite.TimeSeries, error) {
+	var messages [][]byte
+	err := wire.Fields(b, func(num protowire.Number, _ protowire.Type, _ uint64, b []byte) {
+		if num == writeRequestTimeSeries {
+			messages = append(messages, b)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]remotewrite.TimeSeries, 0, len(messages))
+	for _, m := range messages {
+		var labels, samples [][]byte
+		err := wire.Fields(m, func(num protowire.Number, _ protowire.Type, _ uint64, b []byte) {
+			switch num {
+			case timeSeriesLabels:
+				labels = append(labels, b)
+			case timeSeriesSamples:
+				samples = append(samples, b)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		var s remotewrite.TimeSeries
+		for _, b := range labels {
+			var l remotewrite.Label
+			err := wire.Fields(b, func(num protowire.Number, _ protowire.Type, _ uint64, b []byte) {
+				switch num {
+				case labelName:
+					l.Name = string(b)
+				case labelValue:
+					l.Value = string(b)
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+			s.Labels = append(s.Labels, l)
+		}
+		for _, b := range samples {
+			var v remotewrite.Sample
+			err := wire.Fields(b, func(num protowire.Number, _ protowire.Type, u uint64, _ []byte) {
+				switch num {
+				case sampleValue:
+					v.Value = math.Float64frombits(u)
+				case sampleTimestamp:
+					v.Timestamp = int64(u)
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+			s.Samples = append(s.Samples, v)
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// Receiver is an http.Handler accepting write requests, passing their time
+// series to a function.
+type Receiver func([]remotewrite.TimeSeries)
+
+// ServeHTTP implements http.Handler.
+func (f Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Encoding") != "snappy" {
+		http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+		return
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, err = snappy.Decode(nil, b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	series, err := Unmarshal(b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f(series)
+	w.WriteHeader(http.StatusNoContent)
+}
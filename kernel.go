@@ -0,0 +1,215 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+var (
+	kernelUDPRcvbufErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kernel", "udp_rcvbuf_errors_total"),
+		"Number of UDP datagrams dropped by the kernel because the receive buffer of their socket was full.",
+		[]string{"family"}, nil,
+	)
+	kernelUDPInErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kernel", "udp_in_errors_total"),
+		"Number of UDP datagrams the kernel failed to deliver, including receive buffer errors.",
+		[]string{"family"}, nil,
+	)
+	kernelTCPListenOverflows = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kernel", "tcp_listen_overflows_total"),
+		"Number of times the accept queue of a listening TCP socket overflowed.",
+		nil, nil,
+	)
+	kernelTCPListenDrops = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kernel", "tcp_listen_drops_total"),
+		"Number of connection requests dropped by listening TCP sockets.",
+		nil, nil,
+	)
+	kernelListenerQueue = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kernel", "listener_receive_queue"),
+		"Receive queue of the sockets named listens on: bytes waiting to be read for UDP, connections waiting to be accepted for TCP.",
+		[]string{"address", "port", "transport"}, nil,
+	)
+	kernelListenerDrops = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kernel", "listener_drops_total"),
+		"Number of datagrams dropped by the kernel on the UDP sockets named listens on.",
+		[]string{"address", "port"}, nil,
+	)
+)
+
+// tcpListen is the state of listening sockets in /proc/net/tcp.
+const tcpListen = 0x0a
+
+// kernelCollector exports the kernel's network counters relevant to DNS from
+// the network namespace of named, and the receive queues and drops of the
+// sockets named listens on. The counters are shared by all processes of the
+// namespace. UDP sockets are considered listeners if named also listens on
+// their address and port over TCP, which leaves out the sockets of outgoing
+// queries.
+type kernelCollector struct {
+	path   string
+	pidFn  func() (int, error)
+	logger *slog.Logger
+}
+
+func newKernelCollector(logger *slog.Logger, procPath string, pidFn func() (int, error)) *kernelCollector {
+	return &kernelCollector{path: procPath, pidFn: pidFn, logger: logger}
+}
+
+// Describe implements prometheus.Collector.
+func (c *kernelCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- kernelUDPRcvbufErrors
+	ch <- kernelUDPInErrors
+	ch <- kernelTCPListenOverflows
+	ch <- kernelTCPListenDrops
+	ch <- kernelListenerQueue
+	ch <- kernelListenerDrops
+}
+
+// Collect implements prometheus.Collector.
+func (c *kernelCollector) Collect(ch chan<- prometheus.Metric) {
+	pid, err := c.pidFn()
+	if err != nil {
+		c.logger.Error("Couldn't find the pid of named", "err", err)
+		return
+	}
+	fs, err := procfs.NewFS(c.path)
+	if err != nil {
+		c.logger.Error("Couldn't open the proc filesystem", "err", err)
+		return
+	}
+	p, err := fs.Proc(pid)
+	if err != nil {
+		c.logger.Error("Couldn't read the process of named", "pid", pid, "err", err)
+		return
+	}
+
+	if s, err := p.Snmp(); err == nil {
+		counter(ch, kernelUDPRcvbufErrors, s.Udp.RcvbufErrors, "ipv4")
+		counter(ch, kernelUDPInErrors, s.Udp.InErrors, "ipv4")
+	} else {
+		c.logger.Debug("Couldn't read SNMP counters", "pid", pid, "err", err)
+	}
+	if s, err := p.Snmp6(); err == nil {
+		counter(ch, kernelUDPRcvbufErrors, s.Udp6.RcvbufErrors, "ipv6")
+		counter(ch, kernelUDPInErrors, s.Udp6.InErrors, "ipv6")
+	} else {
+		c.logger.Debug("Couldn't read IPv6 SNMP counters", "pid", pid, "err", err)
+	}
+	if s, err := p.Netstat(); err == nil {
+		counter(ch, kernelTCPListenOverflows, s.TcpExt.ListenOverflows)
+		counter(ch, kernelTCPListenDrops, s.TcpExt.ListenDrops)
+	} else {
+		c.logger.Debug("Couldn't read netstat counters", "pid", pid, "err", err)
+	}
+
+	if err := c.collectListeners(ch, p, pid); err != nil {
+		c.logger.Error("Couldn't read the sockets of named", "pid", pid, "err", err)
+	}
+}
+
+// collectListeners exports the receive queues and drops of the sockets named
+// listens on. With reuseport, named opens a socket per thread for each
+// address, which are summed up.
+func (c *kernelCollector) collectListeners(ch chan<- prometheus.Metric, p procfs.Proc, pid int) error {
+	targets, err := p.FileDescriptorTargets()
+	if err != nil {
+		return err
+	}
+	inodes := map[uint64]bool{}
+	for _, t := range targets {
+		var inode uint64
+		if _, err := fmt.Sscanf(t, "socket:[%d]", &inode); err == nil {
+			inodes[inode] = true
+		}
+	}
+
+	// The socket tables of /proc/<pid>/net are those of the network
+	// namespace of named.
+	nfs, err := procfs.NewFS(filepath.Join(c.path, strconv.Itoa(pid)))
+	if err != nil {
+		return err
+	}
+
+	type listener struct{ address, port string }
+	var tcpKeys []listener
+	tcp := map[listener]uint64{}
+	for _, read := range []func() (procfs.NetTCP, error){nfs.NetTCP, nfs.NetTCP6} {
+		sockets, err := read()
+		if err != nil {
+			return err
+		}
+		for _, s := range sockets {
+			if !inodes[s.Inode] || s.St != tcpListen {
+				continue
+			}
+			l := listener{s.LocalAddr.String(), strconv.FormatUint(s.LocalPort, 10)}
+			if _, ok := tcp[l]; !ok {
+				tcpKeys = append(tcpKeys, l)
+			}
+			tcp[l] += s.RxQueue
+		}
+	}
+	for _, l := range tcpKeys {
+		ch <- prometheus.MustNewConstMetric(
+			kernelListenerQueue, prometheus.GaugeValue, float64(tcp[l]), l.address, l.port, "tcp",
+		)
+	}
+
+	var keys []listener
+	queues, drops := map[listener]uint64{}, map[listener]uint64{}
+	for _, read := range []func() (procfs.NetUDP, error){nfs.NetUDP, nfs.NetUDP6} {
+		sockets, err := read()
+		if err != nil {
+			return err
+		}
+		for _, s := range sockets {
+			l := listener{s.LocalAddr.String(), strconv.FormatUint(s.LocalPort, 10)}
+			if _, ok := tcp[l]; !ok || !inodes[s.Inode] || s.RemPort != 0 || !s.RemAddr.IsUnspecified() {
+				continue
+			}
+			if _, ok := queues[l]; !ok {
+				keys = append(keys, l)
+			}
+			queues[l] += s.RxQueue
+			if s.Drops != nil {
+				drops[l] += *s.Drops
+			}
+		}
+	}
+	for _, l := range keys {
+		ch <- prometheus.MustNewConstMetric(
+			kernelListenerQueue, prometheus.GaugeValue, float64(queues[l]), l.address, l.port, "udp",
+		)
+		ch <- prometheus.MustNewConstMetric(
+			kernelListenerDrops, prometheus.CounterValue, float64(drops[l]), l.address, l.port,
+		)
+	}
+	return nil
+}
+
+// counter exports v if the kernel reports it.
+func counter(ch chan<- prometheus.Metric, desc *prometheus.Desc, v *float64, labels ...string) {
+	if v != nil {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, *v, labels...)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/namedconf"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxSOAQueries limits the number of concurrent SOA queries to primaries.
+const maxSOAQueries = 16
+
+var (
+	zonePrimarySerial = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "zone", "primary_serial"),
+		"Serial number of the zone on the primary server.",
+		[]string{"view", "zone", "primary"}, nil,
+	)
+	zoneSerialBehind = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "zone", "serial_behind"),
+		"Number of serials the zone is behind the most recent primary, using serial number arithmetic.",
+		[]string{"view", "zone"}, nil,
+	)
+	zoneBehindSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "zone", "behind_seconds"),
+		"Time since the zone was first seen behind its primaries, 0 if it is up to date.",
+		[]string{"view", "zone"}, nil,
+	)
+)
+
+// zoneLag compares the serials of secondary zones with their primaries. The
+// primaries are queried in the background, as querying all of them on every
+// scrape is too slow for many zones or unreachable primaries.
+type zoneLag struct {
+	config  *configFile
+	timeout time.Duration
+	filter  seriesFilter
+	now     func() time.Time
+	logger  *slog.Logger
+
+	mu sync.Mutex
+	// primaries holds the serials of the last queries by view and zone
+	// name, and by address of the primary. Zones whose primaries all failed
+	// to answer are left out.
+	primaries map[[2]string]map[string]uint32
+	// behindSince is the time each zone lagging behind was first seen
+	// behind.
+	behindSince map[[2]string]time.Time
+}
+
+func newZoneLag(logger *slog.Logger, file *configFile, timeout time.Duration, f seriesFilter) *zoneLag {
+	return &zoneLag{
+		config:      file,
+		timeout:     timeout,
+		filter:      f,
+		now:         time.Now,
+		logger:      logger,
+		primaries:   map[[2]string]map[string]uint32{},
+		behindSince: map[[2]string]time.Time{},
+	}
+}
+
+// describe sends the descriptors of the lag metrics.
+func (l *zoneLag) describe(ch chan<- *prometheus.Desc) {
+	ch <- zonePrimarySerial
+	ch <- zoneSerialBehind
+	ch <- zoneBehindSeconds
+}
+
+// run queries the primaries every interval until ctx is done.
+func (l *zoneLag) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		l.query()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// query queries the serials of the zones loaded from a primary from each of
+// their primaries.
+func (l *zoneLag) query() {
+	conf, err := l.config.load()
+	if err != nil {
+		l.logger.Error("Couldn't parse configuration file", "err", err)
+		return
+	}
+
+	var (
+		keys      = conf.Keys()
+		primaries = map[[2]string]map[string]uint32{}
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, maxSOAQueries)
+	)
+	for _, z := range conf.Zones() {
+		if len(z.Primaries) == 0 || z.Class != "IN" || !l.filter.keepView(z.View) || !l.filter.keepZone(z.Name) {
+			continue
+		}
+		key := [2]string{z.View, z.Name}
+		for _, p := range z.Primaries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				serial, err := l.querySerial(z.Name, p, keys)
+				if err != nil {
+					l.logger.Debug("Couldn't query primary", "zone", z.Name, "primary", p.Address, "err", err)
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if primaries[key] == nil {
+					primaries[key] = map[string]uint32{}
+				}
+				primaries[key][p.Address] = serial
+			}()
+		}
+	}
+	wg.Wait()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.primaries = primaries
+}
+
+// collect exports how far behind the local serials of the zone statistics
+// are from the serials last queried from the primaries.
+func (l *zoneLag) collect(ch chan<- prometheus.Metric, s *bind.Statistics) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	seen := map[[2]string]bool{}
+	for _, v := range s.ZoneViews {
+		for _, z := range v.ZoneData {
+			key := [2]string{v.Name, z.Name}
+			serials, ok := l.primaries[key]
+			if !ok {
+				continue
+			}
+			local, err := strconv.ParseUint(z.Serial, 10, 32)
+			if err != nil {
+				continue
+			}
+			seen[key] = true
+
+			var behind uint32
+			for addr, serial := range serials {
+				ch <- prometheus.MustNewConstMetric(
+					zonePrimarySerial, prometheus.GaugeValue, float64(serial), v.Name, z.Name, addr,
+				)
+				behind = max(behind, serialBehind(uint32(local), serial))
+			}
+			ch <- prometheus.MustNewConstMetric(
+				zoneSerialBehind, prometheus.GaugeValue, float64(behind), v.Name, z.Name,
+			)
+
+			var seconds float64
+			if behind > 0 {
+				since, ok := l.behindSince[key]
+				if !ok {
+					since = now
+					l.behindSince[key] = now
+				}
+				seconds = now.Sub(since).Seconds()
+			} else {
+				delete(l.behindSince, key)
+			}
+			ch <- prometheus.MustNewConstMetric(
+				zoneBehindSeconds, prometheus.GaugeValue, seconds, v.Name, z.Name,
+			)
+		}
+	}
+	for key := range l.behindSince {
+		if !seen[key] {
+			delete(l.behindSince, key)
+		}
+	}
+}
+
+// querySerial queries the SOA serial of a zone from a primary, signing the
+// query if the primary is configured with a key.
+func (l *zoneLag) querySerial(zone string, p namedconf.Primary, keys map[string]namedconf.Key) (uint32, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(zone), dns.TypeSOA)
+	c := &dns.Client{Timeout: l.timeout}
+	if p.Key != "" {
+		k, ok := keys[p.Key]
+		if !ok {
+			return 0, fmt.Errorf("unknown key %q", p.Key)
+		}
+		name := dns.Fqdn(k.Name)
+		c.TsigSecret = map[string]string{name: k.Secret}
+		m.SetTsig(name, dns.Fqdn(k.Algorithm), 300, time.Now().Unix())
+	}
+
+	r, _, err := c.Exchange(m, p.Address)
+	if err != nil {
+		return 0, err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return 0, fmt.Errorf("unexpected rcode %s", dns.RcodeToString[r.Rcode])
+	}
+	for _, rr := range r.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Serial, nil
+		}
+	}
+	return 0, fmt.Errorf("no SOA record in response")
+}
+
+// serialBehind returns how many serials local is behind primary according to
+// RFC 1982. It is 0 if local is equal to or ahead of primary, or if the
+// comparison is undefined.
+func serialBehind(local, primary uint32) uint32 {
+	d := primary - local
+	if d >= 1<<31 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,244 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/otlp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/version"
+)
+
+// otlpExporter periodically gathers the metrics of the exporter and exports
+// them via OTLP. The metrics of BIND's statistics start at BIND's boot time,
+// the other cumulative metrics have no start time.
+type otlpExporter struct {
+	exporter *Exporter
+	bind     prometheus.Gatherer
+	other    prometheus.Gatherer
+	client   *otlp.Client
+	resource []otlp.Attribute
+	exports  *prometheus.CounterVec
+	logger   *slog.Logger
+}
+
+func newOTLPExporter(logger *slog.Logger, e *Exporter, other prometheus.Gatherer, client *otlp.Client, resource []otlp.Attribute) *otlpExporter {
+	r := prometheus.NewRegistry()
+	r.MustRegister(e)
+	return &otlpExporter{
+		exporter: e,
+		bind:     r,
+		other:    other,
+		client:   client,
+		resource: resource,
+		exports: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "exporter",
+			Name:      "otlp_exports_total",
+			Help:      "Number of OTLP export requests sent by result.",
+		}, []string{"result"}),
+		logger: logger,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (o *otlpExporter) Describe(ch chan<- *prometheus.Desc) {
+	o.exports.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (o *otlpExporter) Collect(ch chan<- prometheus.Metric) {
+	o.exports.Collect(ch)
+}
+
+// run exports the metrics every interval until ctx is done.
+func (o *otlpExporter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := o.export(ctx); err != nil {
+			o.exports.WithLabelValues("failure").Inc()
+			o.logger.Error("Couldn't export metrics via OTLP", "err", err)
+		} else {
+			o.exports.WithLabelValues("success").Inc()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *otlpExporter) export(ctx context.Context) error {
+	return o.client.Export(ctx, otlp.Marshal(o.request(time.Now())))
+}
+
+// request gathers the metrics into an export request with points at now.
+func (o *otlpExporter) request(now time.Time) otlp.Request {
+	bindMetrics, err := o.bind.Gather()
+	if err != nil {
+		o.logger.Error("Error gathering BIND metrics", "err", err)
+	}
+	other, err := o.other.Gather()
+	if err != nil {
+		o.logger.Error("Error gathering metrics", "err", err)
+	}
+
+	var (
+		boot  time.Time
+		stats []*dto.MetricFamily
+	)
+	for _, mf := range bindMetrics {
+		if mf.GetName() == prometheus.BuildFQName(namespace, "", "boot_time_seconds") && len(mf.GetMetric()) > 0 {
+			if v := mf.GetMetric()[0].GetGauge().GetValue(); v > 0 {
+				boot = time.Unix(int64(v), 0)
+			}
+		}
+		// The exporter's own metrics, like series_dropped_total, don't
+		// accumulate since BIND's boot.
+		if strings.HasPrefix(mf.GetName(), exporter+"_") {
+			other = append(other, mf)
+		} else {
+			stats = append(stats, mf)
+		}
+	}
+
+	resource := []otlp.Attribute{{Key: "service.name", Value: "bind"}}
+	if v := o.exporter.bindVersion(); v != "" {
+		resource = append(resource, otlp.Attribute{Key: "service.version", Value: v})
+	}
+	for _, a := range o.resource {
+		resource = setAttribute(resource, a)
+	}
+	return otlp.Request{
+		Resource:     resource,
+		ScopeName:    exporter,
+		ScopeVersion: version.Version,
+		Metrics:      append(otlpMetrics(stats, boot, now), otlpMetrics(other, time.Time{}, now)...),
+	}
+}
+
+func setAttribute(attrs []otlp.Attribute, a otlp.Attribute) []otlp.Attribute {
+	for i := range attrs {
+		if attrs[i].Key == a.Key {
+			attrs[i].Value = a.Value
+			return attrs
+		}
+	}
+	return append(attrs, a)
+}
+
+// otlpMetrics converts gathered metrics to OTLP metrics. Counters become
+// monotonic sums without the _total suffix, gauges and untyped metrics
+// gauges, and histograms explicit-bucket histograms. Cumulative points start
+// at start unless it is zero.
+func otlpMetrics(mfs []*dto.MetricFamily, start, now time.Time) []otlp.Metric {
+	var metrics []otlp.Metric
+	for _, mf := range mfs {
+		m := otlp.Metric{Name: mf.GetName(), Description: mf.GetHelp()}
+		for _, pm := range mf.GetMetric() {
+			p := otlp.Point{Time: now}
+			if pm.TimestampMs != nil {
+				p.Time = time.UnixMilli(pm.GetTimestampMs())
+			}
+			for _, l := range pm.GetLabel() {
+				p.Attributes = append(p.Attributes, otlp.Attribute{Key: l.GetName(), Value: l.GetValue()})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				m.Type, m.Monotonic = otlp.Sum, true
+				p.Start, p.Value = start, pm.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				m.Type, p.Value = otlp.Gauge, pm.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				m.Type, p.Value = otlp.Gauge, pm.GetUntyped().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				m.Type = otlp.Histogram
+				p.Start = start
+				histogramPoint(&p, pm.GetHistogram())
+			case dto.MetricType_SUMMARY:
+				m.Type = otlp.Summary
+				s := pm.GetSummary()
+				p.Start, p.Count, p.Sum = start, s.GetSampleCount(), s.GetSampleSum()
+				for _, q := range s.GetQuantile() {
+					p.Quantiles = append(p.Quantiles, otlp.Quantile{Quantile: q.GetQuantile(), Value: q.GetValue()})
+				}
+			default:
+				continue
+			}
+			m.Points = append(m.Points, p)
+		}
+		if len(m.Points) == 0 {
+			continue
+		}
+		if m.Monotonic {
+			m.Name = strings.TrimSuffix(m.Name, "_total")
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// histogramPoint sets the buckets of p from the cumulative buckets of a
+// Prometheus histogram.
+func histogramPoint(p *otlp.Point, h *dto.Histogram) {
+	p.Count, p.Sum = h.GetSampleCount(), h.GetSampleSum()
+	buckets := append([]*dto.Bucket{}, h.GetBucket()...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].GetUpperBound() < buckets[j].GetUpperBound() })
+	var cumulative uint64
+	for _, b := range buckets {
+		if math.IsInf(b.GetUpperBound(), 1) {
+			break
+		}
+		p.Bounds = append(p.Bounds, b.GetUpperBound())
+		p.BucketCounts = append(p.BucketCounts, b.GetCumulativeCount()-cumulative)
+		cumulative = b.GetCumulativeCount()
+	}
+	p.BucketCounts = append(p.BucketCounts, p.Count-cumulative)
+}
+
+type resourceAttributes []otlp.Attribute
+
+// String implements flag.Value.
+func (r *resourceAttributes) String() string {
+	var s []string
+	for _, a := range *r {
+		s = append(s, a.Key+"="+a.Value)
+	}
+	return strings.Join(s, ",")
+}
+
+// Set implements flag.Value.
+func (r *resourceAttributes) Set(value string) error {
+	key, v, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid resource attribute %q, expected key=value", value)
+	}
+	*r = setAttribute(*r, otlp.Attribute{Key: key, Value: v})
+	return nil
+}
+
+// IsCumulative makes the flag repeatable.
+func (r *resourceAttributes) IsCumulative() bool {
+	return true
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/probe"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	probeSuccess = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "success"),
+		"Whether the last run of the probe got the expected response.",
+		[]string{"probe"}, nil,
+	)
+	probeDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "duration_seconds"),
+		"Duration of the last run of the probe.",
+		[]string{"probe"}, nil,
+	)
+	probeDNSSECValidated = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "dnssec_validated"),
+		"Whether the last response of the probe had the AD flag set.",
+		[]string{"probe"}, nil,
+	)
+)
+
+// probeCollector runs the configured probes against named in the background
+// and exports the results of their last run.
+type probeCollector struct {
+	probes []probe.Probe
+	target string
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	results map[string]probe.Result
+}
+
+func newProbeCollector(logger *slog.Logger, c *probe.Config, target string) *probeCollector {
+	return &probeCollector{
+		probes:  c.Probes,
+		target:  target,
+		logger:  logger,
+		results: map[string]probe.Result{},
+	}
+}
+
+// run runs the probes every interval until ctx is done.
+func (c *probeCollector) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs all probes concurrently.
+func (c *probeCollector) runOnce(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range c.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := p.Run(ctx, c.target)
+			if r.Err != nil {
+				c.logger.Debug("Probe failed", "probe", p.Name, "err", r.Err)
+			}
+			c.mu.Lock()
+			c.results[p.Name] = r
+			c.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+// Describe implements prometheus.Collector.
+func (c *probeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- probeSuccess
+	ch <- probeDuration
+	ch <- probeDNSSECValidated
+}
+
+// Collect implements prometheus.Collector.
+func (c *probeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, r := range c.results {
+		ch <- prometheus.MustNewConstMetric(probeSuccess, prometheus.GaugeValue, float64(boolToUint(r.Success)), name)
+		ch <- prometheus.MustNewConstMetric(probeDuration, prometheus.GaugeValue, r.Duration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(probeDNSSECValidated, prometheus.GaugeValue, float64(boolToUint(r.Validated)), name)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+// instanceLabel is the label the metrics of named's process are exported
+// with when named processes are discovered by --bind.process.
+const instanceLabel = "named_instance"
+
+// processCacheTTL is how long a discovered pid is reused, so that the
+// collectors of a scrape don't each walk /proc to find it.
+const processCacheTTL = time.Second
+
+// instanceRE matches the instance names of --bind.process.
+var instanceRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+var processDiscovered = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "exporter", "process_discovered"),
+	"Whether the process of named was found in the last scrape.",
+	[]string{instanceLabel}, nil,
+)
+
+// namedProcess is a named instance whose process information is exported.
+// The instance is empty for the process in --bind.pid-file.
+type namedProcess struct {
+	instance string
+	pidFn    func() (int, error)
+}
+
+// processMatcher matches the processes of a named instance by name or
+// command line.
+type processMatcher struct {
+	instance string
+	re       *regexp.Regexp
+}
+
+type processMatchers []processMatcher
+
+// String implements flag.Value.
+func (m *processMatchers) String() string {
+	var s []string
+	for _, p := range *m {
+		s = append(s, p.instance+"="+p.re.String())
+	}
+	return strings.Join(s, ",")
+}
+
+// Set implements flag.Value. Values are of the form [instance=]regexp, the
+// instance defaulting to "named". The part before the first "=" is only an
+// instance if it is a valid instance name, so "-c=/etc/named.conf" is a
+// regexp; other regexps containing "=" need an instance, e.g. "named=a=b".
+func (m *processMatchers) Set(value string) error {
+	instance, expr, ok := strings.Cut(value, "=")
+	if !ok || !instanceRE.MatchString(instance) {
+		instance, expr = "named", value
+	}
+	for _, p := range *m {
+		if p.instance == instance {
+			return fmt.Errorf("duplicated instance %q", instance)
+		}
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid process regexp %q: %w", expr, err)
+	}
+	*m = append(*m, processMatcher{instance: instance, re: re})
+	return nil
+}
+
+// IsCumulative makes the flag repeatable.
+func (m *processMatchers) IsCumulative() bool {
+	return true
+}
+
+// newProcessPidFn returns a function finding the pid of the process whose
+// name or command line, with its arguments separated by spaces, matches re.
+// If several processes match, e.g. while named forks on startup, the oldest
+// one is returned. The exporter itself is never matched. The result is
+// reused for processCacheTTL.
+func newProcessPidFn(fs procfs.FS, re *regexp.Regexp) func() (int, error) {
+	var (
+		mu      sync.Mutex
+		pid     int
+		err     error
+		updated time.Time
+	)
+	return func() (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if now := time.Now(); updated.IsZero() || now.Sub(updated) >= processCacheTTL {
+			pid, err = findProcess(fs, re)
+			updated = now
+		}
+		return pid, err
+	}
+}
+
+func findProcess(fs procfs.FS, re *regexp.Regexp) (int, error) {
+	procs, err := fs.AllProcs()
+	if err != nil {
+		return 0, err
+	}
+	self := os.Getpid()
+	var (
+		pid   int
+		start uint64
+	)
+	for _, p := range procs {
+		if p.PID == self || !processMatches(p, re) {
+			continue
+		}
+		// Processes may exit while they are read.
+		s, err := p.Stat()
+		if err != nil {
+			continue
+		}
+		if pid == 0 || s.Starttime < start {
+			pid, start = p.PID, s.Starttime
+		}
+	}
+	if pid == 0 {
+		return 0, fmt.Errorf("no process matches %q", re)
+	}
+	return pid, nil
+}
+
+func processMatches(p procfs.Proc, re *regexp.Regexp) bool {
+	if comm, err := p.Comm(); err == nil && re.MatchString(comm) {
+		return true
+	}
+	cmdline, err := p.CmdLine()
+	return err == nil && len(cmdline) > 0 && re.MatchString(strings.Join(cmdline, " "))
+}
+
+// discoveryCollector exports whether the processes of named are found, as
+// the process collectors silently export nothing when they aren't.
+type discoveryCollector struct {
+	processes []namedProcess
+	logger    *slog.Logger
+}
+
+func newDiscoveryCollector(logger *slog.Logger, processes []namedProcess) *discoveryCollector {
+	return &discoveryCollector{processes: processes, logger: logger}
+}
+
+// Describe implements prometheus.Collector.
+func (c *discoveryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- processDiscovered
+}
+
+// Collect implements prometheus.Collector.
+func (c *discoveryCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, p := range c.processes {
+		var v float64
+		if _, err := p.pidFn(); err == nil {
+			v = 1
+		} else {
+			c.logger.Debug("Couldn't find the process of named", "instance", p.instance, "err", err)
+		}
+		ch <- prometheus.MustNewConstMetric(processDiscovered, prometheus.GaugeValue, v, p.instance)
+	}
+}
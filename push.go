@@ -0,0 +1,194 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/remotewrite"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	pushMinBackoff = time.Second
+	pushMaxBackoff = 5 * time.Minute
+)
+
+// pusher periodically gathers the metrics of the exporter and sends them to
+// a remote-write endpoint. Requests which can't be sent are buffered and
+// retried with exponential backoff, oldest first.
+type pusher struct {
+	gatherer prometheus.Gatherer
+	client   *remotewrite.Client
+	buffer   *remotewrite.Buffer
+	external []remotewrite.Label
+	requests *prometheus.CounterVec
+	dropped  prometheus.Counter
+	buffered prometheus.GaugeFunc
+	logger   *slog.Logger
+}
+
+func newPusher(logger *slog.Logger, g prometheus.Gatherer, client *remotewrite.Client, buffer *remotewrite.Buffer, external []remotewrite.Label) *pusher {
+	return &pusher{
+		gatherer: g,
+		client:   client,
+		buffer:   buffer,
+		external: external,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "exporter",
+			Name:      "remote_write_requests_total",
+			Help:      "Number of remote-write requests sent by result.",
+		}, []string{"result"}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "exporter",
+			Name:      "remote_write_dropped_requests_total",
+			Help:      "Number of remote-write requests dropped because they were rejected or the buffer was full.",
+		}),
+		buffered: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "exporter",
+			Name:      "remote_write_buffered_requests",
+			Help:      "Number of remote-write requests waiting to be sent.",
+		}, func() float64 { return float64(buffer.Len()) }),
+		logger: logger,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (p *pusher) Describe(ch chan<- *prometheus.Desc) {
+	p.requests.Describe(ch)
+	p.dropped.Describe(ch)
+	p.buffered.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (p *pusher) Collect(ch chan<- prometheus.Metric) {
+	p.requests.Collect(ch)
+	p.dropped.Collect(ch)
+	p.buffered.Collect(ch)
+}
+
+// run gathers the metrics every interval and sends them until ctx is done.
+// While a request is retried, the metrics gathered meanwhile are buffered.
+func (p *pusher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var (
+		backoff = pushMinBackoff
+		retry   <-chan time.Time
+	)
+	p.gather()
+	for {
+		if retry == nil {
+			if err := p.send(ctx); err != nil {
+				p.logger.Warn("Couldn't send metrics, retrying", "backoff", backoff, "buffered", p.buffer.Len(), "err", err)
+				retry = time.After(backoff)
+				backoff = min(2*backoff, pushMaxBackoff)
+			} else {
+				backoff = pushMinBackoff
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.gather()
+		case <-retry:
+			retry = nil
+		}
+	}
+}
+
+// gather adds a request with the current metrics to the buffer.
+func (p *pusher) gather() {
+	mfs, err := p.gatherer.Gather()
+	if err != nil {
+		// Like promhttp, send what could be gathered.
+		p.logger.Error("Error gathering metrics", "err", err)
+	}
+	series := remotewrite.FromMetricFamilies(mfs, time.Now(), p.external)
+	n, err := p.buffer.Push(remotewrite.Encode(remotewrite.Marshal(series)))
+	if err != nil {
+		p.logger.Error("Couldn't buffer metrics", "err", err)
+	}
+	if n > 0 {
+		p.dropped.Add(float64(n))
+		p.logger.Warn("Remote-write buffer full, dropped the oldest requests", "dropped", n)
+	}
+}
+
+// send sends the buffered requests until one fails with a recoverable error,
+// which is returned. Requests failing otherwise are dropped.
+func (p *pusher) send(ctx context.Context) error {
+	for {
+		req := p.buffer.Peek()
+		if req == nil {
+			return nil
+		}
+		err := p.client.Store(ctx, req)
+		var recoverable remotewrite.RecoverableError
+		switch {
+		case err == nil:
+			p.requests.WithLabelValues("success").Inc()
+		case errors.As(err, &recoverable):
+			p.requests.WithLabelValues("retry").Inc()
+			return err
+		default:
+			p.requests.WithLabelValues("rejected").Inc()
+			p.dropped.Inc()
+			p.logger.Error("Remote-write request rejected, dropping it", "err", err)
+		}
+		if err := p.buffer.Pop(); err != nil {
+			return err
+		}
+	}
+}
+
+type externalLabels []remotewrite.Label
+
+// String implements flag.Value.
+func (l *externalLabels) String() string {
+	var s []string
+	for _, e := range *l {
+		s = append(s, e.Name+"="+e.Value)
+	}
+	return strings.Join(s, ",")
+}
+
+// Set implements flag.Value.
+func (l *externalLabels) Set(value string) error {
+	name, v, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid external label %q, expected name=value", value)
+	}
+	for _, e := range *l {
+		if e.Name == name {
+			return fmt.Errorf("duplicated external label %q", name)
+		}
+	}
+	*l = append(*l, remotewrite.Label{Name: name, Value: v})
+	return nil
+}
+
+// IsCumulative makes the flag repeatable.
+func (l *externalLabels) IsCumulative() bool {
+	return true
+}
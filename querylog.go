@@ -0,0 +1,172 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/querylog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queryLogTopClients = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "querylog", "top_client_queries"),
+		"Estimated number of queries of the most active clients in the last complete window.",
+		[]string{"rank", "client"}, nil,
+	)
+	queryLogTopNames = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "querylog", "top_name_queries"),
+		"Estimated number of queries for the most queried names in the last complete window.",
+		[]string{"rank", "name"}, nil,
+	)
+)
+
+// queryLogCollector counts the queries of named's query log. The most active
+// clients and most queried names are estimated per window in constant memory
+// and exported with their rank.
+type queryLogCollector struct {
+	queries  *prometheus.CounterVec
+	unparsed prometheus.Counter
+	topN     int
+	window   time.Duration
+	now      func() time.Time
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	clients   *querylog.TopK
+	names     *querylog.TopK
+	windowEnd time.Time
+	// topClients and topNames are the results of the last complete window.
+	topClients []querylog.Item
+	topNames   []querylog.Item
+}
+
+func newQueryLogCollector(logger *slog.Logger, topN int, window time.Duration) *queryLogCollector {
+	// Tracking more elements than exported makes the estimate of the top
+	// ones more accurate.
+	capacity := 10 * topN
+	c := &queryLogCollector{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "querylog",
+			Name:      "queries_total",
+			Help:      "Number of queries in the query log.",
+		}, []string{"view", "qtype", "transport"}),
+		unparsed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "querylog",
+			Name:      "unparsed_lines_total",
+			Help:      "Number of query log lines which couldn't be parsed as a query.",
+		}),
+		topN:    topN,
+		window:  window,
+		now:     time.Now,
+		logger:  logger,
+		clients: querylog.NewTopK(capacity),
+		names:   querylog.NewTopK(capacity),
+	}
+	c.windowEnd = c.now().Add(window)
+	return c
+}
+
+// tail follows the query log at path until ctx is done.
+func (c *queryLogCollector) tail(ctx context.Context, path string, interval time.Duration) {
+	t := querylog.NewTailer(path)
+	t.TooLong = c.unparsed.Inc
+	defer t.Close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Poll(c.observe); err != nil {
+			c.logger.Error("Couldn't read query log", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// observe counts a line of the query log.
+func (c *queryLogCollector) observe(line string) {
+	q, ok := querylog.ParseLine(line)
+	if !ok {
+		c.unparsed.Inc()
+		return
+	}
+	view := q.View
+	if view == "" {
+		view = "_default"
+	}
+	c.queries.WithLabelValues(view, q.Type, q.Transport).Inc()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rotate()
+	c.clients.Add(q.Client)
+	c.names.Add(q.Name)
+}
+
+// rotate starts a new window once the current one ended. It must be called
+// with mu held.
+func (c *queryLogCollector) rotate() {
+	now := c.now()
+	if now.Before(c.windowEnd) {
+		return
+	}
+	if now.Sub(c.windowEnd) >= c.window {
+		// No query was logged in the last complete window.
+		c.topClients, c.topNames = nil, nil
+	} else {
+		c.topClients, c.topNames = c.clients.Top(c.topN), c.names.Top(c.topN)
+	}
+	c.clients.Reset()
+	c.names.Reset()
+	c.windowEnd = c.windowEnd.Add(now.Sub(c.windowEnd).Truncate(c.window) + c.window)
+}
+
+// Describe implements prometheus.Collector.
+func (c *queryLogCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.queries.Describe(ch)
+	c.unparsed.Describe(ch)
+	ch <- queryLogTopClients
+	ch <- queryLogTopNames
+}
+
+// Collect implements prometheus.Collector.
+func (c *queryLogCollector) Collect(ch chan<- prometheus.Metric) {
+	c.queries.Collect(ch)
+	c.unparsed.Collect(ch)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rotate()
+	for i, item := range c.topClients {
+		ch <- prometheus.MustNewConstMetric(
+			queryLogTopClients, prometheus.GaugeValue, float64(item.Count), strconv.Itoa(i+1), item.Key,
+		)
+	}
+	for i, item := range c.topNames {
+		ch <- prometheus.MustNewConstMetric(
+			queryLogTopNames, prometheus.GaugeValue, float64(item.Count), strconv.Itoa(i+1), item.Key,
+		)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log/slog"
+
+	"github.com/prometheus-community/bind_exporter/bind/rndc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	rndcUp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "rndc", "up"),
+		"Was the rndc status query successful?",
+		nil, nil,
+	)
+	serverRunning = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "server_running"),
+		"Whether the server is up and running.",
+		nil, nil,
+	)
+	serverReloading = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "server_reloading"),
+		"Whether a reload or reconfiguration is in progress.",
+		nil, nil,
+	)
+	zones = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "zones"),
+		"Number of zones.",
+		nil, nil,
+	)
+	zonesAutomatic = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "zones_automatic"),
+		"Number of automatic zones.",
+		nil, nil,
+	)
+	zoneTransfersRunning = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "zone_transfers_running"),
+		"Number of zone transfers running.",
+		nil, nil,
+	)
+	zoneTransfersDeferred = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "zone_transfers_deferred"),
+		"Number of zone transfers deferred.",
+		nil, nil,
+	)
+	soaQueriesInProgress = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "soa_queries_in_progress"),
+		"Number of SOA queries in progress.",
+		nil, nil,
+	)
+	recursiveClientsLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "recursive_clients_limit"),
+		"Limit of concurrent recursive clients.",
+		[]string{"kind"}, nil,
+	)
+	tcpClients = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "tcp_clients"),
+		"Number of current TCP clients.",
+		nil, nil,
+	)
+	tcpClientsLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "tcp_clients_limit"),
+		"Limit of concurrent TCP clients.",
+		nil, nil,
+	)
+)
+
+// rndcCollector exports the server status reported by the control channel.
+// The current number of recursive clients is part of the server statistics
+// and not exported again.
+type rndcCollector struct {
+	client *rndc.Client
+	logger *slog.Logger
+}
+
+func newRNDCCollector(logger *slog.Logger, c *rndc.Client) *rndcCollector {
+	return &rndcCollector{client: c, logger: logger}
+}
+
+// Describe implements prometheus.Collector.
+func (c *rndcCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rndcUp
+	ch <- serverRunning
+	ch <- serverReloading
+	ch <- zones
+	ch <- zonesAutomatic
+	ch <- zoneTransfersRunning
+	ch <- zoneTransfersDeferred
+	ch <- soaQueriesInProgress
+	ch <- recursiveClientsLimit
+	ch <- tcpClients
+	ch <- tcpClientsLimit
+}
+
+// Collect implements prometheus.Collector.
+func (c *rndcCollector) Collect(ch chan<- prometheus.Metric) {
+	s, err := c.client.Status()
+	if err != nil {
+		c.logger.Error("Couldn't retrieve rndc status", "err", err)
+		ch <- prometheus.MustNewConstMetric(rndcUp, prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(rndcUp, prometheus.GaugeValue, 1)
+
+	for desc, v := range map[*prometheus.Desc]uint64{
+		serverRunning:         boolToUint(s.Running),
+		serverReloading:       boolToUint(s.Reloading),
+		zones:                 s.Zones,
+		zonesAutomatic:        s.AutomaticZones,
+		zoneTransfersRunning:  s.XfersRunning,
+		zoneTransfersDeferred: s.XfersDeferred,
+		soaQueriesInProgress:  s.SOAQueries,
+		tcpClients:            s.TCPClients,
+		tcpClientsLimit:       s.TCPClientsLimit,
+	} {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(v))
+	}
+	ch <- prometheus.MustNewConstMetric(
+		recursiveClientsLimit, prometheus.GaugeValue, float64(s.RecursiveClientsSoftLimit), "soft",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		recursiveClientsLimit, prometheus.GaugeValue, float64(s.RecursiveClientsHardLimit), "hard",
+	)
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+var (
+	threadCPUSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "process", "thread_cpu_seconds_total"),
+		"Total user and system CPU time spent by the current threads of named, by thread name without its number.",
+		[]string{"thread_group"}, nil,
+	)
+	threads = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "process", "threads"),
+		"Number of threads of named by thread name without its number and scheduler state.",
+		[]string{"thread_group", "state"}, nil,
+	)
+)
+
+// threadStates maps the scheduler states of /proc/<pid>/task/<tid>/stat to
+// label values. Unknown states are exported as reported.
+var threadStates = map[string]string{
+	"R": "running",
+	"S": "sleeping",
+	"D": "disk_sleep",
+	"T": "stopped",
+	"t": "tracing_stop",
+	"Z": "zombie",
+	"X": "dead",
+	"I": "idle",
+}
+
+// threadCollector exports the CPU time and states of the threads of named,
+// grouped by name. named names its threads after their role and number, e.g.
+// isc-net-0000 or isc-worker0003. As threads exiting take their CPU time
+// with them, the counters can decrease when named reconfigures its threads.
+type threadCollector struct {
+	fs     procfs.FS
+	pidFn  func() (int, error)
+	logger *slog.Logger
+}
+
+func newThreadCollector(logger *slog.Logger, fs procfs.FS, pidFn func() (int, error)) *threadCollector {
+	return &threadCollector{fs: fs, pidFn: pidFn, logger: logger}
+}
+
+// Describe implements prometheus.Collector.
+func (c *threadCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- threadCPUSeconds
+	ch <- threads
+}
+
+// Collect implements prometheus.Collector.
+func (c *threadCollector) Collect(ch chan<- prometheus.Metric) {
+	pid, err := c.pidFn()
+	if err != nil {
+		c.logger.Error("Couldn't find the pid of named", "err", err)
+		return
+	}
+	procs, err := c.fs.AllThreads(pid)
+	if err != nil {
+		c.logger.Error("Couldn't list the threads of named", "pid", pid, "err", err)
+		return
+	}
+
+	type key struct{ group, state string }
+	cpu := map[string]float64{}
+	counts := map[key]int{}
+	for _, p := range procs {
+		// Threads may exit while they are read.
+		s, err := p.Stat()
+		if err != nil {
+			continue
+		}
+		g := threadGroup(s.Comm)
+		cpu[g] += s.CPUTime()
+		state, ok := threadStates[s.State]
+		if !ok {
+			state = s.State
+		}
+		counts[key{g, state}]++
+	}
+
+	for g, v := range cpu {
+		ch <- prometheus.MustNewConstMetric(threadCPUSeconds, prometheus.CounterValue, v, g)
+	}
+	for k, n := range counts {
+		ch <- prometheus.MustNewConstMetric(threads, prometheus.GaugeValue, float64(n), k.group, k.state)
+	}
+}
+
+// threadGroup returns the name of a thread without its trailing number, e.g.
+// isc-net for isc-net-0000 and isc-worker for isc-worker0003.
+func threadGroup(comm string) string {
+	g := strings.TrimRight(comm, "0123456789")
+	if g != comm {
+		g = strings.TrimRight(g, "-_")
+	}
+	if g == "" {
+		return comm
+	}
+	return g
+}